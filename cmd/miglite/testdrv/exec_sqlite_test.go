@@ -1,11 +1,13 @@
 package testdrv
 
 import (
+	"context"
 	"database/sql"
 	"path/filepath"
 	"testing"
 
 	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/config"
 	"github.com/gookit/miglite/internal/database"
 	"github.com/gookit/miglite/pkg/command"
 	"github.com/gookit/miglite/pkg/migcom"
@@ -14,9 +16,9 @@ import (
 func TestExecMultiSQL(t *testing.T) {
 	t.Run("commit all statements", func(t *testing.T) {
 		dbPath := filepath.Join(t.TempDir(), "commit.db")
-		setCommandSQLiteDB(t, dbPath)
+		r := newSQLiteRunner(t, dbPath, "")
 
-		err := command.HandleExec(command.ExecOption{
+		results, err := r.Exec(command.ExecOption{
 			SQLOrFile: `CREATE TABLE items(id INTEGER PRIMARY KEY, name TEXT);
 				INSERT INTO items(name) VALUES ('first');
 				SELECT id, name FROM items;
@@ -24,6 +26,10 @@ func TestExecMultiSQL(t *testing.T) {
 			Yes: true,
 		})
 		assert.NoErr(t, err)
+		assert.Len(t, results, 1)
+		assert.Eq(t, 3, results[0].Index)
+		assert.Eq(t, []string{"id", "name"}, results[0].Columns)
+		assert.Eq(t, [][]any{{int64(1), "first"}}, results[0].Rows)
 
 		db, err := sql.Open("sqlite", dbPath)
 		assert.Require(t, assert.NoErr(t, err))
@@ -36,9 +42,9 @@ func TestExecMultiSQL(t *testing.T) {
 
 	t.Run("rollback all statements", func(t *testing.T) {
 		dbPath := filepath.Join(t.TempDir(), "rollback.db")
-		setCommandSQLiteDB(t, dbPath)
+		r := newSQLiteRunner(t, dbPath, "")
 
-		err := command.HandleExec(command.ExecOption{
+		_, err := r.Exec(command.ExecOption{
 			SQLOrFile: `CREATE TABLE items(id INTEGER PRIMARY KEY, name TEXT);
 				INSERT INTO items(name) VALUES ('first');
 				INSERT INTO missing_table(name) VALUES ('fail');`,
@@ -55,10 +61,66 @@ func TestExecMultiSQL(t *testing.T) {
 	})
 }
 
-func setCommandSQLiteDB(t *testing.T, dbPath string) {
+func newSQLiteRunner(t *testing.T, dbPath, migPath string) *command.Runner {
 	t.Helper()
 	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", dbPath)
 	assert.Require(t, assert.NoErr(t, err))
-	command.SetDB(db)
-	t.Cleanup(func() { command.SetDB(nil) })
+	t.Cleanup(db.SilentClose)
+
+	r := command.NewRunner(&config.Config{
+		Database:   config.Database{Driver: migcom.DriverSQLite, SqlDriver: "sqlite", DSN: dbPath},
+		Migrations: config.Migrations{Path: migPath, Recursive: true},
+	})
+	r.SetDB(db)
+	return r
+}
+
+func TestRunner_externalDB(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "external.db")
+	sqlDB, err := sql.Open("sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer sqlDB.Close()
+
+	r := command.NewRunner(&config.Config{
+		Database:   config.Database{Driver: migcom.DriverSQLite, SqlDriver: "sqlite", DSN: dbPath},
+		Migrations: config.Migrations{Path: "../../../testdata/migrations/sqlite", Recursive: true},
+	})
+	r.SetDB(database.NewWithSqlDB(migcom.DriverSQLite, sqlDB))
+
+	// multiple operations on the injected connection
+	assert.NoErr(t, r.Init(command.InitOption{}))
+	_, err = r.Status(command.StatusOption{})
+	assert.NoErr(t, err)
+	_, err = r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	// the connection should not be closed
+	assert.NoErr(t, sqlDB.Ping())
+	assert.Eq(t, sqlDB, r.DB().DB)
+}
+
+func TestExecTrigger_sqlite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "trigger.db")
+	r := newSQLiteRunner(t, dbPath, "")
+
+	_, err := r.Exec(command.ExecOption{
+		SQLOrFile: `CREATE TABLE items(id INTEGER PRIMARY KEY, name TEXT);
+			CREATE TABLE logs(item_id INTEGER, msg TEXT);
+			CREATE TRIGGER trg_items AFTER INSERT ON items
+			BEGIN
+				INSERT INTO logs VALUES (NEW.id, CASE WHEN NEW.name = 'a' THEN 'is a' ELSE 'other' END);
+				INSERT INTO logs VALUES (NEW.id, 'done');
+			END;
+			INSERT INTO items(name) VALUES ('a');`,
+		Yes: true,
+	})
+	assert.NoErr(t, err)
+
+	db, err := sql.Open("sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.Close()
+
+	var count int
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count))
+	assert.Eq(t, 2, count)
 }
@@ -0,0 +1,99 @@
+package testdrv
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestGoMigration_sqlite(t *testing.T) {
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "gomig.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+	assert.Require(t, assert.NoErr(t, db.InitSchema()))
+
+	mig, err := migration.NewGoMigration("20260105-102400-create-items",
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "CREATE TABLE items(id INTEGER PRIMARY KEY, name TEXT)")
+			return err
+		},
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DROP TABLE items")
+			return err
+		},
+	)
+	assert.Require(t, assert.NoErr(t, err))
+
+	executor := migration.NewExecutor(db, false)
+	assert.NoErr(t, executor.ExecuteUp(mig))
+	applied, _, err := migration.IsApplied(db, mig.Version)
+	assert.NoErr(t, err)
+	assert.True(t, applied)
+
+	// the Go-code migration is not reported as missing when it is not registered
+	record, err := migration.GetRecord(db, mig.Version)
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.KindGo, record.Kind)
+	records, err := migration.GetRecords(db)
+	assert.NoErr(t, err)
+	assert.Empty(t, migration.FindDrifts(records, nil))
+
+	assert.NoErr(t, executor.ExecuteDown(mig))
+	applied, status, err := migration.IsApplied(db, mig.Version)
+	assert.NoErr(t, err)
+	assert.False(t, applied)
+	assert.Eq(t, migration.StatusDown, status)
+}
+
+func TestExecutorContext_sqlite(t *testing.T) {
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "ctx.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+	// the canceled transaction is rolled back in background, use one connection to wait for it.
+	db.SetMaxOpenConns(1)
+	assert.Require(t, assert.NoErr(t, db.InitSchema()))
+
+	// the migration waits until the context is done
+	mig, err := migration.NewGoMigration("20260105-102400-slow-migration",
+		func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, "CREATE TABLE items(id INTEGER PRIMARY KEY)"); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil,
+	)
+	assert.Require(t, assert.NoErr(t, err))
+
+	t.Run("timeout", func(t *testing.T) {
+		executor := migration.NewExecutor(db, false)
+		executor.SetTimeout(50 * time.Millisecond)
+
+		res := executor.UpContext(context.Background(), mig)
+		assert.True(t, res.IsFailed())
+		assert.ErrSubMsg(t, res.Err, context.DeadlineExceeded.Error())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		res := migration.NewExecutor(db, false).UpContext(ctx, mig)
+		assert.True(t, res.IsFailed())
+		assert.ErrSubMsg(t, res.Err, context.Canceled.Error())
+	})
+
+	// the transaction is rolled back
+	applied, _, err := migration.IsApplied(db, mig.Version)
+	assert.NoErr(t, err)
+	assert.False(t, applied)
+	_, err = db.Exec("SELECT COUNT(*) FROM items")
+	assert.Err(t, err)
+}
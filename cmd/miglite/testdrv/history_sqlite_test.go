@@ -0,0 +1,76 @@
+package testdrv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestHistory_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	files := map[string]string{
+		"20251105-102430-create-users.sql": "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);\n-- Migrate:DOWN\nDROP TABLE users;",
+		"20251105-102431-bad-sql.sql":      "-- Migrate:UP\nCREATE TABLE bad_sql(id INTEGER PRIMARY KEY;",
+		"20251105-102432-create-roles.sql": "-- Migrate:UP\nCREATE TABLE roles(id INTEGER PRIMARY KEY);",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(migPath, name), []byte(contents), 0644))
+	}
+
+	dbPath := filepath.Join(tmpDir, "history.db")
+	ctx := context.Background()
+	// up -> failed
+	_, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.Err(t, err)
+	// skip the bad one, down -> up
+	_, err = newSQLiteRunner(t, dbPath, migPath).Skip(ctx, command.SkipOption{FileNames: []string{"20251105-102431-bad-sql"}})
+	assert.NoErr(t, err)
+	_, err = newSQLiteRunner(t, dbPath, migPath).Down(ctx, command.DownOption{Yes: true, Number: 1})
+	assert.NoErr(t, err)
+	_, err = newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	events, err := newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 6)
+	var statuses []string
+	for _, ev := range events {
+		statuses = append(statuses, ev.Action+":"+ev.Status)
+	}
+	assert.Eq(t, []string{"up:applied", "up:failed", "skip:skipped", "down:rolled", "up:applied", "up:applied"}, statuses)
+	assert.Eq(t, "20251105-102431-bad-sql.sql", events[1].Version)
+	assert.NotEmpty(t, events[1].Message)
+	assert.NotEmpty(t, events[0].Operator)
+
+	// filter by version and status
+	events, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Version: "create-users", Status: "up"})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 2)
+	events, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Status: migration.ResultFailed})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 1)
+	events, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Limit: 2})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 2)
+	assert.Eq(t, "20251105-102432-create-roles.sql", events[1].Version)
+
+	// filter by time range
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	events, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Until: yesterday})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 0)
+	events, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Since: yesterday})
+	assert.NoErr(t, err)
+	assert.Len(t, events, 6)
+
+	_, err = newSQLiteRunner(t, dbPath, migPath).History(command.HistoryOption{Since: "2025/11/05"})
+	assert.ErrSubMsg(t, err, "invalid time")
+}
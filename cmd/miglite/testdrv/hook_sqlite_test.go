@@ -0,0 +1,88 @@
+package testdrv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+type recordHook struct {
+	migration.NopHook
+	events []string
+	veto   string
+}
+
+func (h *recordHook) BeforeAll(_ context.Context, direction string) error {
+	h.events = append(h.events, "beforeAll:"+direction)
+	return nil
+}
+
+func (h *recordHook) BeforeMigration(_ context.Context, mig *migration.Migration, direction string, tx *sql.Tx) error {
+	if tx == nil {
+		return errors.New("tx is nil")
+	}
+	if mig.Version == h.veto {
+		return errors.New("vetoed")
+	}
+	h.events = append(h.events, "before:"+direction+":"+mig.Version)
+	return nil
+}
+
+func (h *recordHook) AfterMigration(_ context.Context, mig *migration.Migration, direction string, _ *sql.Tx) error {
+	h.events = append(h.events, "after:"+direction+":"+mig.Version)
+	return nil
+}
+
+func (h *recordHook) OnError(_ context.Context, mig *migration.Migration, direction string, _ error) {
+	h.events = append(h.events, "error:"+direction+":"+mig.Version)
+}
+
+func (h *recordHook) AfterAll(_ context.Context, report *migration.RunReport) {
+	h.events = append(h.events, "afterAll:"+report.Action)
+}
+
+func TestRunnerHooks_sqlite(t *testing.T) {
+	const first = "20251105-102325-create-users-table.sql"
+	migPath := "../../../testdata/migrations/sqlite"
+
+	t.Run("run hooks", func(t *testing.T) {
+		r := newSQLiteRunner(t, filepath.Join(t.TempDir(), "hook.db"), migPath)
+		hook := &recordHook{}
+		r.AddHook(hook)
+
+		_, err := r.Up(context.Background(), command.UpOption{Yes: true, Number: 1})
+		assert.NoErr(t, err)
+		_, err = r.Down(context.Background(), command.DownOption{Yes: true, Number: 1})
+		assert.NoErr(t, err)
+
+		assert.Eq(t, []string{
+			"beforeAll:up", "before:up:" + first, "after:up:" + first, "afterAll:up",
+			"beforeAll:down", "before:down:" + first, "after:down:" + first, "afterAll:down",
+		}, hook.events)
+	})
+
+	t.Run("veto migration", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "veto.db")
+		r := newSQLiteRunner(t, dbPath, migPath)
+		hook := &recordHook{veto: first}
+		r.AddHook(hook)
+
+		report, err := r.Up(context.Background(), command.UpOption{Yes: true})
+		assert.ErrSubMsg(t, err, "vetoed")
+		assert.Eq(t, 1, report.Count(migration.ResultFailed))
+		assert.Eq(t, []string{"beforeAll:up", "error:up:" + first, "afterAll:up"}, hook.events)
+
+		db, err := sql.Open("sqlite", dbPath)
+		assert.Require(t, assert.NoErr(t, err))
+		defer db.Close()
+
+		var count int
+		assert.Err(t, db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count))
+	})
+}
@@ -0,0 +1,82 @@
+package testdrv
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/importer"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestImportGoose_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "goose")
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(srcDir, 0755)))
+	files := map[string]string{
+		"00001_create_users.sql": "-- +goose Up\nCREATE TABLE users(id INTEGER);\n-- +goose Down\nDROP TABLE users;\n",
+		"00002_add_age.sql":      "-- +goose Up\nALTER TABLE users ADD COLUMN age INTEGER;\n",
+		"00003_add_name.sql":     "-- +goose Up\nALTER TABLE users ADD COLUMN name TEXT;\n",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(srcDir, name), []byte(contents), 0644))
+	}
+
+	// the goose history: 1, 2 applied, 3 rolled back
+	dbPath := filepath.Join(tmpDir, "import.db")
+	db, err := sql.Open("sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE goose_db_version(id INTEGER PRIMARY KEY AUTOINCREMENT, version_id INTEGER, is_applied INTEGER, tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO goose_db_version(version_id, is_applied) VALUES (0, 1), (1, 1), (2, 1), (3, 1), (3, 0);
+		CREATE TABLE users(id INTEGER, age INTEGER);`)
+	assert.Require(t, assert.NoErr(t, err))
+
+	ctx := context.Background()
+	opt := command.ImportOption{From: importer.Goose, Src: srcDir, History: true}
+	assert.NoErr(t, newSQLiteRunner(t, dbPath, migPath).Import(ctx, opt))
+
+	migrations, err := migration.FindMigrations(migPath, false)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 3)
+
+	var count int
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM "+database.SchemaTableName+" WHERE status = 'up'").Scan(&count))
+	assert.Eq(t, 2, count)
+
+	// import again, nothing changed
+	assert.NoErr(t, newSQLiteRunner(t, dbPath, migPath).Import(ctx, opt))
+	migrations, err = migration.FindMigrations(migPath, false)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 3)
+
+	// only the version 3 is pending
+	report, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultApplied))
+	assert.StrContains(t, report.Results[2].Version, "-add_name.sql")
+
+	// the seeded records have the checksum of files, so the changed file is reported as drift
+	records, err := migration.GetRecords(newSQLiteRunner(t, dbPath, migPath).DB())
+	assert.NoErr(t, err)
+	assert.NotEmpty(t, records)
+	for _, record := range records {
+		assert.NotEmpty(t, record.Checksum)
+		assert.Eq(t, migration.KindSQL, record.Kind)
+	}
+
+	assert.NoErr(t, os.WriteFile(migrations[0].FilePath, []byte("-- Migrate:UP\nCREATE TABLE users(id INTEGER, name TEXT);\n"), 0644))
+	for _, mig := range migrations {
+		assert.NoErr(t, mig.Parse())
+	}
+	drifts := migration.FindDrifts(records, migrations)
+	assert.Len(t, drifts, 1)
+	assert.Eq(t, migrations[0].Version, drifts[0].Version)
+	assert.Eq(t, migration.DriftModified, drifts[0].Reason)
+}
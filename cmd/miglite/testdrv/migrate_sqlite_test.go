@@ -1,11 +1,14 @@
 package testdrv
 
 import (
+	"context"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/gookit/goutil/x/assert"
 	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/command"
 	"github.com/gookit/miglite/pkg/migcom"
 	"github.com/gookit/miglite/pkg/migration"
 )
@@ -38,3 +41,350 @@ func TestMigration_sqlite(t *testing.T) {
 		}
 	}
 }
+
+func TestRunFilePair_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	files := map[string]string{
+		"000001_create_users.up.sql":   "CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);",
+		"000001_create_users.down.sql": "DROP TABLE users;",
+		"000002_add_age.up.sql":        "ALTER TABLE users ADD COLUMN age INTEGER;",
+		"000002_add_age.down.sql":      "ALTER TABLE users DROP COLUMN age;",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(migPath, name), []byte(contents), 0644))
+	}
+
+	dbPath := filepath.Join(tmpDir, "pair.db")
+	ctx := context.Background()
+	report, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, report.Count(migration.ResultApplied))
+	assert.Eq(t, "000001_create_users", report.Results[0].Version)
+
+	// run again, all applied
+	report, err = newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 0, report.Count(migration.ResultApplied))
+
+	report, err = newSQLiteRunner(t, dbPath, migPath).Down(ctx, command.DownOption{Yes: true, Number: 2})
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, report.Count(migration.ResultRolled))
+	assert.Eq(t, "000002_add_age", report.Results[0].Version)
+}
+
+func TestRunRepeatable_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	viewFile := filepath.Join(migPath, "R-users-view.sql")
+	files := map[string]string{
+		"20251105-102430-create-users.sql": "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);\n-- Migrate:DOWN\nDROP TABLE users;",
+		"R-users-view.sql":                 "-- Migrate:UP\nDROP VIEW IF EXISTS v_users;\nCREATE VIEW v_users AS SELECT id FROM users;",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(migPath, name), []byte(contents), 0644))
+	}
+
+	dbPath := filepath.Join(tmpDir, "repeatable.db")
+	ctx := context.Background()
+	report, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, report.Count(migration.ResultApplied))
+	// run after the versioned migrations
+	assert.Eq(t, "R-users-view.sql", report.Results[1].Version)
+
+	// not changed, skip it
+	report, err = newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 0, report.Count(migration.ResultApplied))
+
+	// changed, status is outdated and run it again
+	assert.NoErr(t, os.WriteFile(viewFile, []byte("-- Migrate:UP\nDROP VIEW IF EXISTS v_users;\nCREATE VIEW v_users AS SELECT id, name FROM users;"), 0644))
+	r := newSQLiteRunner(t, dbPath, migPath)
+	migrations, err := migration.FindMigrations(migPath, true)
+	assert.NoErr(t, err)
+	assert.NoErr(t, migrations[1].Parse())
+	statuses, err := migration.GetMigrationsStatus(r.DB(), migrations)
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.StatusOutdated, statuses[1].Status)
+
+	report, err = r.Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultApplied))
+	assert.Eq(t, "R-users-view.sql", report.Results[1].Version)
+
+	record, err := migration.GetRecord(newSQLiteRunner(t, dbPath, migPath).DB(), "R-users-view.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, migrations[1].Checksum, record.Checksum)
+
+	// down only rolls back the versioned migrations
+	report, err = newSQLiteRunner(t, dbPath, migPath).Down(ctx, command.DownOption{Yes: true, Number: 1})
+	assert.NoErr(t, err)
+	assert.Eq(t, "20251105-102430-create-users.sql", report.Results[0].Version)
+}
+
+func TestUpgradeSchema_sqlite(t *testing.T) {
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "upgrade.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+
+	// the table created by old version, without the meta table and new columns
+	_, err = db.Exec("CREATE TABLE z_schema_migrations(version VARCHAR(160) PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP, status VARCHAR(24))")
+	assert.NoErr(t, err)
+	_, err = db.Exec("INSERT INTO z_schema_migrations(version, status) VALUES ('20251105-102430-create-users.sql', 'up'), ('R-users-view.sql', 'up'), ('rollup_0002.sql', 'up')")
+	assert.NoErr(t, err)
+
+	assert.NoErr(t, db.InitSchema())
+	ver, err := db.SchemaVersion()
+	assert.NoErr(t, err)
+	assert.Eq(t, database.SchemaVersion, ver)
+
+	record, err := migration.GetRecord(db, "20251105-102430-create-users.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.StatusUp, record.Status)
+	assert.Empty(t, record.Checksum)
+	assert.Empty(t, record.AppliedBy)
+
+	// the kind of the old repeatable record is filled, it is excluded by the kind, not by the version prefix
+	record, err = migration.GetRecord(db, "R-users-view.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.KindRepeatable, record.Kind)
+	applied, err := migration.GetAppliedSortedByVersion(db, 10)
+	assert.NoErr(t, err)
+	assert.Len(t, applied, 2)
+	assert.Eq(t, "rollup_0002.sql", applied[0].Version)
+
+	// run again, it is already the latest
+	assert.NoErr(t, db.InitSchema())
+
+	// the schema version is saved by upsert
+	_, err = db.Exec("UPDATE z_schema_migrations_meta SET meta_value = '2'")
+	assert.NoErr(t, err)
+	assert.NoErr(t, db.UpgradeSchema())
+	ver, err = db.SchemaVersion()
+	assert.NoErr(t, err)
+	assert.Eq(t, database.SchemaVersion, ver)
+
+	// the table has checksum column, but no meta table
+	db2, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "upgrade2.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db2.SilentClose()
+	_, err = db2.Exec("CREATE TABLE z_schema_migrations(version VARCHAR(160) PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP, status VARCHAR(24), checksum VARCHAR(64))")
+	assert.NoErr(t, err)
+	assert.NoErr(t, db2.UpgradeSchema())
+	ver, err = db2.SchemaVersion()
+	assert.NoErr(t, err)
+	assert.Eq(t, database.SchemaVersion, ver)
+
+	// the query error of columns is returned, not treated as missing columns
+	db2.SetTableName("not_exists")
+	assert.ErrSubMsg(t, db2.UpgradeSchema(), "failed to query columns of not_exists")
+}
+
+func TestRunDryRun_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	contents := "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);\n-- Migrate:DOWN\nDROP TABLE users;"
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102430-create-users.sql"), []byte(contents), 0644))
+
+	dbPath := filepath.Join(tmpDir, "dry-run.db")
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+
+	// the migrations tables are not created
+	report, err := newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{DryRun: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultDryRun))
+	report, err = newSQLiteRunner(t, dbPath, migPath).Down(context.Background(), command.DownOption{DryRun: true, Number: 1})
+	assert.NoErr(t, err)
+	assert.Empty(t, report.Results)
+	tables, err := db.ShowTables()
+	assert.NoErr(t, err)
+	assert.Empty(t, tables)
+
+	// the table created by old version is not upgraded
+	_, err = db.Exec("CREATE TABLE z_schema_migrations(version VARCHAR(160) PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP, status VARCHAR(24))")
+	assert.NoErr(t, err)
+	_, err = newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{DryRun: true})
+	assert.ErrSubMsg(t, err, "the migrations table z_schema_migrations needs to be upgraded")
+	exists, err := db.TableExists(db.MetaTableName())
+	assert.NoErr(t, err)
+	assert.False(t, exists)
+
+	// read the applied migrations after upgraded
+	assert.NoErr(t, db.InitSchema())
+	exists, err = db.TableExists("main." + db.MetaTableName())
+	assert.NoErr(t, err)
+	assert.True(t, exists)
+	_, err = newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	report, err = newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{DryRun: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultIgnored))
+	report, err = newSQLiteRunner(t, dbPath, migPath).Down(context.Background(), command.DownOption{DryRun: true, Number: 1})
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"DROP TABLE users"}, report.Results[0].SQL)
+}
+
+func TestRecordInfo_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	contents := "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);\n-- Migrate:DOWN\nDROP TABLE users;"
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102430-create-users.sql"), []byte(contents), 0644))
+
+	dbPath := filepath.Join(tmpDir, "info.db")
+	_, err := newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	record, err := migration.GetRecord(newSQLiteRunner(t, dbPath, migPath).DB(), "20251105-102430-create-users.sql")
+	assert.NoErr(t, err)
+	assert.Len(t, record.Checksum, 64)
+	assert.Eq(t, "create-users", record.Description)
+	assert.Eq(t, command.Version, record.MigliteVersion)
+	assert.StrContains(t, record.AppliedBy, "@")
+	assert.True(t, record.ExecutionMs >= 0)
+	assert.Eq(t, migration.KindSQL, record.Kind)
+}
+
+func TestValidate_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	files := map[string]string{
+		"20251105-102430-create-users.sql": "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);",
+		"20251105-102431-create-roles.sql": "-- Migrate:UP\nCREATE TABLE roles(id INTEGER PRIMARY KEY);",
+		"20251105-102432-create-posts.sql": "-- Migrate:UP\nCREATE TABLE posts(id INTEGER PRIMARY KEY);",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(migPath, name), []byte(contents), 0644))
+	}
+
+	dbPath := filepath.Join(tmpDir, "validate.db")
+	_, err := newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	drifts, err := newSQLiteRunner(t, dbPath, migPath).Validate(command.ValidateOption{})
+	assert.NoErr(t, err)
+	assert.Empty(t, drifts)
+
+	// edit and delete the applied files
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102430-create-users.sql"), []byte("-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);"), 0644))
+	assert.NoErr(t, os.Remove(filepath.Join(migPath, "20251105-102432-create-posts.sql")))
+
+	drifts, err = newSQLiteRunner(t, dbPath, migPath).Validate(command.ValidateOption{})
+	assert.ErrSubMsg(t, err, "found 2 applied migrations changed or deleted")
+	assert.Len(t, drifts, 2)
+	assert.Eq(t, migration.DriftModified, drifts[0].Reason)
+	assert.Eq(t, "20251105-102430-create-users.sql", drifts[0].Version)
+	assert.Eq(t, migration.DriftMissing, drifts[1].Reason)
+	assert.Eq(t, "20251105-102432-create-posts.sql", drifts[1].Version)
+
+	// status and up only warn it
+	status, err := newSQLiteRunner(t, dbPath, migPath).Status(command.StatusOption{})
+	assert.NoErr(t, err)
+	assert.Len(t, status.Drifts, 2)
+	_, err = newSQLiteRunner(t, dbPath, migPath).Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+}
+
+func TestRunRequires_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	ordersPath := filepath.Join(tmpDir, "orders")
+	usersPath := filepath.Join(tmpDir, "users")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(ordersPath, 0755)))
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(usersPath, 0755)))
+
+	// the orders migration has earlier timestamp, but it requires the users migration
+	orders := "-- requires: 20251105-102432-create-users\n-- Migrate:UP\nCREATE TABLE orders(id INTEGER PRIMARY KEY);\nINSERT INTO users(id) VALUES (1);\n" +
+		"-- Migrate:DOWN\nDELETE FROM users;\nDROP TABLE orders;"
+	users := "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);\n-- Migrate:DOWN\nDROP TABLE users;"
+	assert.NoErr(t, os.WriteFile(filepath.Join(ordersPath, "20251105-102430-create-orders.sql"), []byte(orders), 0644))
+	assert.NoErr(t, os.WriteFile(filepath.Join(usersPath, "20251105-102432-create-users.sql"), []byte(users), 0644))
+
+	ctx := context.Background()
+	dbPath := filepath.Join(tmpDir, "requires.db")
+	migPath := ordersPath + "," + usersPath
+	report, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, report.Count(migration.ResultApplied))
+	assert.Eq(t, "20251105-102432-create-users.sql", report.Results[0].Version)
+
+	// roll back in the reverse order of run
+	report, err = newSQLiteRunner(t, dbPath, migPath).Down(ctx, command.DownOption{Yes: true, Number: 1})
+	assert.NoErr(t, err)
+	assert.Eq(t, "20251105-102430-create-orders.sql", report.Results[0].Version)
+
+	// circular requires is reported before run any migration
+	users = "-- requires: 20251105-102430-create-orders.sql\n" + users
+	assert.NoErr(t, os.WriteFile(filepath.Join(usersPath, "20251105-102432-create-users.sql"), []byte(users), 0644))
+	_, err = newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "circular requires")
+}
+
+func TestRunOutOfOrder_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102432-create-users.sql"), []byte("-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);"), 0644))
+
+	ctx := context.Background()
+	dbPath := filepath.Join(tmpDir, "order.db")
+	_, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	// a branch merges an older-timestamped file after newer one is applied
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102430-create-roles.sql"), []byte("-- Migrate:UP\nCREATE TABLE roles(id INTEGER PRIMARY KEY);"), 0644))
+
+	r := newSQLiteRunner(t, dbPath, migPath)
+	r.Config().Migrations.OutOfOrder = migration.OutOfOrderError
+	status, err := r.Status(command.StatusOption{})
+	assert.NoErr(t, err)
+	assert.Len(t, status.OutOfOrder, 1)
+	assert.Eq(t, migration.StatusPending, status.Statuses[0].Status)
+	_, err = r.Up(ctx, command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "found 1 out-of-order pending migrations")
+
+	// invalid policy
+	r = newSQLiteRunner(t, dbPath, migPath)
+	r.Config().Migrations.OutOfOrder = "ignore"
+	_, err = r.Up(ctx, command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "invalid out-of-order policy")
+
+	r = newSQLiteRunner(t, dbPath, migPath)
+	r.Config().Migrations.OutOfOrder = migration.OutOfOrderError
+	report, err := r.Up(ctx, command.UpOption{Yes: true, AllowOutOfOrder: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultApplied))
+	assert.Eq(t, "20251105-102430-create-roles.sql", report.Results[0].Version)
+}
+
+func TestRunOutOfOrder_driverOption_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	files := map[string]string{
+		// only for postgres, it is ignored and never recorded on sqlite
+		"20251105-102430-pg-extension.sql": "-- Migrate-option: drivers=postgres\n-- Migrate:UP\nCREATE EXTENSION IF NOT EXISTS pgcrypto;",
+		"20251105-102431-create-users.sql": "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);",
+	}
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(migPath, name), []byte(contents), 0644))
+	}
+
+	ctx := context.Background()
+	dbPath := filepath.Join(tmpDir, "driver.db")
+	for i := 0; i < 2; i++ {
+		r := newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Migrations.OutOfOrder = migration.OutOfOrderError
+		_, err := r.Up(ctx, command.UpOption{Yes: true})
+		assert.NoErr(t, err)
+		status, err := r.Status(command.StatusOption{})
+		assert.NoErr(t, err)
+		assert.Empty(t, status.OutOfOrder)
+	}
+}
@@ -0,0 +1,148 @@
+package testdrv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestExecutorOptions_sqlite(t *testing.T) {
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "options.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+	assert.Require(t, assert.NoErr(t, db.InitSchema()))
+	executor := migration.NewExecutor(db, false)
+
+	newMig := func(version, contents string) *migration.Migration {
+		mig := &migration.Migration{FileName: version, Version: version, Contents: contents}
+		assert.Require(t, assert.NoErr(t, mig.ParseContents()))
+		return mig
+	}
+
+	t.Run("not for driver", func(t *testing.T) {
+		mig := newMig("20260105-102400-pg-only.sql", "-- Migrate-option: drivers=postgres\n-- Migrate:UP\nCREATE TABLE pg_items(id INTEGER);")
+		res := executor.Up(mig)
+		assert.Eq(t, migration.ResultIgnored, res.Status)
+		assert.StrContains(t, res.Message, "sqlite")
+
+		applied, _, err := migration.IsApplied(db, mig.Version)
+		assert.NoErr(t, err)
+		assert.False(t, applied)
+	})
+
+	t.Run("no transaction", func(t *testing.T) {
+		mig := newMig("20260105-102500-vacuum.sql", "-- Migrate-option: no-transaction\n-- Migrate:UP\nVACUUM;")
+		res := executor.Up(mig)
+		assert.NoErr(t, res.Err)
+		assert.Eq(t, migration.ResultApplied, res.Status)
+
+		applied, _, err := migration.IsApplied(db, mig.Version)
+		assert.NoErr(t, err)
+		assert.True(t, applied)
+	})
+
+	t.Run("no transaction half-applied", func(t *testing.T) {
+		mig := newMig("20260105-102700-half.sql", `-- Migrate-option: no-transaction
+-- Migrate:UP
+CREATE TABLE half_items(id INTEGER);
+INSERT INTO half_items VALUES (1);
+
+INSERT INTO missing_table VALUES (1);
+`)
+		res := executor.Up(mig)
+		assert.True(t, res.IsFailed())
+		assert.Eq(t, 2, res.Statements)
+
+		var dirtyErr *migration.DirtyError
+		assert.True(t, errors.As(res.Err, &dirtyErr))
+		assert.Eq(t, 2, dirtyErr.Executed)
+		assert.Eq(t, 3, dirtyErr.Total)
+		assert.ErrSubMsg(t, res.Err, "at 20260105-102700-half.sql:6 (statement 3/3)")
+
+		applied, status, err := migration.IsApplied(db, mig.Version)
+		assert.NoErr(t, err)
+		assert.False(t, applied)
+		assert.Eq(t, migration.StatusDirty, status)
+
+		// the executed statements are not rolled back
+		var count int
+		assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM half_items").Scan(&count))
+		assert.Eq(t, 1, count)
+	})
+
+	t.Run("migration timeout", func(t *testing.T) {
+		mig, err := migration.NewGoMigration("20260105-102600-slow", func(ctx context.Context, tx *sql.Tx) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil, func(m *migration.Migration) {
+			m.Options.Timeout = 50 * time.Millisecond
+		})
+		assert.Require(t, assert.NoErr(t, err))
+
+		res := executor.Up(mig)
+		assert.True(t, res.IsFailed())
+		assert.ErrSubMsg(t, res.Err, context.DeadlineExceeded.Error())
+	})
+}
+
+func TestExecutorStatementError_sqlite(t *testing.T) {
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", filepath.Join(t.TempDir(), "stmt.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+	assert.Require(t, assert.NoErr(t, db.InitSchema()))
+
+	mig := &migration.Migration{
+		FileName: "20260105-102400-bad.sql",
+		FilePath: "migrations/20260105-102400-bad.sql",
+		Version:  "20260105-102400-bad.sql",
+		Contents: "-- Migrate:UP\nCREATE TABLE stmt_items(id INTEGER);\n\nINSERT INTO missing_table VALUES (1);\n",
+	}
+	assert.Require(t, assert.NoErr(t, mig.ParseContents()))
+
+	res := migration.NewExecutor(db, false).Up(mig)
+	assert.True(t, res.IsFailed())
+	assert.ErrSubMsg(t, res.Err, "at migrations/20260105-102400-bad.sql:4 (statement 2/2)")
+
+	// the transaction is rolled back
+	_, err = db.Exec("SELECT COUNT(*) FROM stmt_items")
+	assert.Err(t, err)
+}
+
+func TestRunUpDriverSections_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20260105-102400-create-items.sql"), []byte(`-- Migrate:UP driver=postgres
+CREATE TABLE items(id BIGSERIAL PRIMARY KEY, name TEXT);
+
+-- Migrate:UP driver=sqlite
+CREATE TABLE items(id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+
+-- Migrate:DOWN
+DROP TABLE items;
+`), 0644))
+
+	dbPath := filepath.Join(tmpDir, "driver.db")
+	r := newSQLiteRunner(t, dbPath, migPath)
+	report, err := r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, report.Count(migration.ResultApplied))
+
+	db, err := sql.Open("sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.Close()
+
+	var ddl string
+	assert.NoErr(t, db.QueryRow("SELECT sql FROM sqlite_master WHERE name = 'items'").Scan(&ddl))
+	assert.StrContains(t, ddl, "AUTOINCREMENT")
+}
@@ -0,0 +1,48 @@
+package testdrv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/migcom"
+)
+
+func TestMSSqlProvider(t *testing.T) {
+	provider, err := database.GetSqlProvider(migcom.DriverMSSQL)
+	assert.Require(t, assert.NoErr(t, err))
+
+	table := provider.QuoteTable("dbo.z_schema_migrations")
+	metaTable := provider.QuoteTable("dbo.z_schema_migrations_meta")
+	historyTable := provider.QuoteTable("dbo.z_schema_migrations_history")
+	sqlList := map[string]string{
+		"QueryAll":                  provider.QueryAll(table),
+		"QueryOne":                  provider.QueryOne(table),
+		"QueryStatus":               provider.QueryStatus(table),
+		"QueryExists":               provider.QueryExists(table),
+		"InsertMigration":           provider.InsertMigration(table),
+		"UpdateMigration":           provider.UpdateMigration(table),
+		"GetAppliedSortedByVersion": provider.GetAppliedSortedByVersion(table),
+		"UpdateRecordInfo":          provider.UpdateRecordInfo(table),
+		"QueryMeta":                 provider.QueryMeta(metaTable),
+		"InsertMeta":                provider.InsertMeta(metaTable),
+		"InsertHistory":             provider.InsertHistory(historyTable),
+		"QueryHistory":              provider.QueryHistory(historyTable),
+		"QueryTableExists":          provider.QueryTableExists("dbo.z_schema_migrations"),
+	}
+
+	// T-SQL uses @pN parameters, does not support LIMIT and SELECT EXISTS(...)
+	for name, sqlStr := range sqlList {
+		assert.NotContains(t, sqlStr, "?", name)
+		assert.NotContains(t, sqlStr, "$1", name)
+		assert.NotContains(t, sqlStr, "LIMIT", name)
+		assert.False(t, strings.HasPrefix(sqlStr, "SELECT EXISTS"), name)
+	}
+
+	assert.Eq(t, "SELECT CASE WHEN EXISTS(SELECT 1 FROM [dbo].[z_schema_migrations] WHERE version = @p1) THEN 1 ELSE 0 END", sqlList["QueryExists"])
+	assert.StrContains(t, sqlList["GetAppliedSortedByVersion"], "SELECT TOP (@p2) version, applied_at FROM [dbo].[z_schema_migrations] WHERE status = @p1")
+	assert.StrContains(t, sqlList["InsertMeta"], "MERGE INTO [dbo].[z_schema_migrations_meta] WITH (HOLDLOCK)")
+	assert.Eq(t, "SELECT CASE WHEN OBJECT_ID(N'[dbo].[z_schema_migrations]', N'U') IS NULL THEN 0 ELSE 1 END", sqlList["QueryTableExists"])
+	assert.Eq(t, "ALTER TABLE [dbo].[z_schema_migrations] ADD applied_by NVARCHAR(160)", provider.AddColumn(table, "applied_by", "VARCHAR(160)"))
+}
@@ -0,0 +1,96 @@
+package testdrv
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestRunUpReport_sqlite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "report.db")
+	r := newSQLiteRunner(t, dbPath, "../../../testdata/migrations/sqlite")
+
+	report, err := r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.StatusUp, report.Action)
+	assert.Eq(t, 4, report.Total)
+	assert.Eq(t, 4, report.Count(migration.ResultApplied))
+	assert.NoErr(t, report.Err())
+
+	var statements int
+	for _, res := range report.Results {
+		assert.Eq(t, migration.ResultApplied, res.Status)
+		assert.NotEmpty(t, res.Version)
+		statements += res.Statements
+	}
+	assert.Eq(t, 2, report.Results[1].Statements)
+	assert.Eq(t, 5, statements)
+}
+
+func TestMultiRunner_sqlite(t *testing.T) {
+	dir := t.TempDir()
+	migPath := "../../../testdata/migrations/sqlite"
+
+	r1 := newSQLiteRunner(t, filepath.Join(dir, "app1.db"), migPath)
+	r2 := newSQLiteRunner(t, filepath.Join(dir, "app2.db"), migPath)
+	r2.SetTableName("app2_migrations")
+
+	var wg sync.WaitGroup
+	reports := make([]*migration.RunReport, 2)
+	errs := make([]error, 2)
+	for i, r := range []*command.Runner{r1, r2} {
+		wg.Add(1)
+		go func(i int, r *command.Runner) {
+			defer wg.Done()
+			reports[i], errs[i] = r.Up(context.Background(), command.UpOption{Yes: true})
+		}(i, r)
+	}
+	wg.Wait()
+
+	for i := range reports {
+		assert.NoErr(t, errs[i])
+		assert.Eq(t, 4, reports[i].Count(migration.ResultApplied))
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "app2.db"))
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.Close()
+
+	var count int
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM app2_migrations").Scan(&count))
+	assert.Eq(t, 4, count)
+	assert.Err(t, db.QueryRow("SELECT COUNT(*) FROM z_schema_migrations").Scan(&count))
+}
+
+func TestRunUpDirty_sqlite(t *testing.T) {
+	dir := t.TempDir()
+	migDir := filepath.Join(dir, "migrations")
+	assert.NoErr(t, os.MkdirAll(migDir, 0755))
+	assert.NoErr(t, os.WriteFile(filepath.Join(migDir, "20260105-102400-half.sql"), []byte(`-- Migrate-option: no-transaction
+-- Migrate:UP
+CREATE TABLE half_items(id INTEGER);
+INSERT INTO missing_table VALUES (1);
+`), 0644))
+
+	r := newSQLiteRunner(t, filepath.Join(dir, "dirty.db"), migDir)
+	_, err := r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "half-applied(1/2 statements executed)")
+
+	// up is blocked by the dirty migration
+	report, err := r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "is half-applied(dirty)")
+	assert.Eq(t, 1, report.Count(migration.ResultFailed))
+
+	// mark it as done by skip
+	_, err = r.Skip(context.Background(), command.SkipOption{FileNames: []string{"20260105-102400-half"}})
+	assert.NoErr(t, err)
+	_, err = r.Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+}
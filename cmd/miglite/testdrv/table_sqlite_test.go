@@ -0,0 +1,100 @@
+package testdrv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/internal/config"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestQuoteTable(t *testing.T) {
+	tests := map[string]string{
+		migcom.DriverMySQL:    "`ops`.`schema_migrations`",
+		migcom.DriverPostgres: `"ops"."schema_migrations"`,
+		migcom.DriverSQLite:   `"ops"."schema_migrations"`,
+		migcom.DriverMSSQL:    "[ops].[schema_migrations]",
+	}
+	for driver, want := range tests {
+		provider, err := database.GetSqlProvider(driver)
+		assert.Require(t, assert.NoErr(t, err))
+		assert.Eq(t, want, provider.QuoteTable("ops.schema_migrations"), driver)
+	}
+
+	// postgres folds the unquoted identifiers to lower case
+	provider, err := database.GetSqlProvider(migcom.DriverPostgres)
+	assert.Require(t, assert.NoErr(t, err))
+	assert.Eq(t, `"ops"."schemamigrations"`, provider.QuoteTable("ops.SchemaMigrations"))
+	provider, err = database.GetSqlProvider(migcom.DriverSQLite)
+	assert.Require(t, assert.NoErr(t, err))
+	assert.Eq(t, `"SchemaMigrations"`, provider.QuoteTable("SchemaMigrations"))
+
+	assert.NoErr(t, database.CheckTableName("schema_migrations"))
+	assert.NoErr(t, database.CheckTableName("ops.schema_migrations"))
+	assert.Err(t, database.CheckTableName("ops.schema migrations"))
+	assert.Err(t, database.CheckTableName("users; DROP TABLE users"))
+	assert.Err(t, database.CheckTableName(""))
+}
+
+func TestMigrationsTable_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	contents := "-- Migrate:UP\nCREATE TABLE users(id INTEGER PRIMARY KEY);\n-- Migrate:DOWN\nDROP TABLE users;"
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20251105-102430-create-users.sql"), []byte(contents), 0644))
+
+	dbPath := filepath.Join(tmpDir, "table.db")
+	db, err := database.NewDB(migcom.DriverSQLite, "sqlite", dbPath)
+	assert.Require(t, assert.NoErr(t, err))
+	defer db.SilentClose()
+
+	newRunner := func(table string) *command.Runner {
+		db, err := database.NewDB(migcom.DriverSQLite, "sqlite", dbPath)
+		assert.Require(t, assert.NoErr(t, err))
+		t.Cleanup(db.SilentClose)
+
+		r := command.NewRunner(&config.Config{
+			Database:   config.Database{Driver: migcom.DriverSQLite, SqlDriver: "sqlite", DSN: dbPath},
+			Migrations: config.Migrations{Path: migPath, Recursive: true, Table: table},
+		})
+		r.SetDB(db)
+		return r
+	}
+
+	_, err = newRunner("main.app_migrations").Up(context.Background(), command.UpOption{Yes: true})
+	assert.NoErr(t, err)
+
+	var count int
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM app_migrations").Scan(&count))
+	assert.Eq(t, 1, count)
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM app_migrations_history").Scan(&count))
+	assert.Eq(t, 1, count)
+	assert.NoErr(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = ?", database.SchemaTableName).Scan(&count))
+	assert.Eq(t, 0, count)
+
+	// the applied migration is read from the configured table
+	status, err := newRunner("main.app_migrations").Status(command.StatusOption{})
+	assert.NoErr(t, err)
+	assert.Eq(t, migration.StatusUp, status.Statuses[0].Status)
+	db.SetTableName("main.app_migrations")
+
+	// the migrations tables are excluded
+	res, err := newRunner("main.app_migrations").Show(command.ShowOption{Tables: true})
+	assert.NoErr(t, err)
+	assert.Contains(t, res.Tables, "users")
+	assert.NotContains(t, res.Tables, "app_migrations")
+	assert.NotContains(t, res.Tables, "app_migrations_meta")
+	assert.NotContains(t, res.Tables, "app_migrations_history")
+	records, err := migration.GetRecords(db)
+	assert.NoErr(t, err)
+	assert.Len(t, records, 1)
+
+	_, err = newRunner("app migrations").Up(context.Background(), command.UpOption{Yes: true})
+	assert.ErrSubMsg(t, err, "invalid migrations table name")
+}
@@ -0,0 +1,84 @@
+package testdrv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func TestRunUpVars_sqlite(t *testing.T) {
+	tmpDir := t.TempDir()
+	migPath := filepath.Join(tmpDir, "migrations")
+	assert.Require(t, assert.NoErr(t, os.MkdirAll(migPath, 0755)))
+	assert.NoErr(t, os.WriteFile(filepath.Join(migPath, "20260105-102400-create-items.sql"), []byte(`-- Migrate:UP
+CREATE TABLE ${prefix}items(id INTEGER, name TEXT DEFAULT {{ quote .Vars.name }}); -- ${prefix} in comment
+-- Migrate:DOWN
+DROP TABLE ${prefix}items;
+`), 0644))
+
+	dbPath := filepath.Join(tmpDir, "vars.db")
+	ctx := context.Background()
+
+	t.Run("no vars", func(t *testing.T) {
+		// not rendered without vars
+		report, err := newSQLiteRunner(t, dbPath, migPath).Up(ctx, command.UpOption{DryRun: true})
+		assert.NoErr(t, err)
+		assert.Eq(t, []string{"CREATE TABLE ${prefix}items(id INTEGER, name TEXT DEFAULT {{ quote .Vars.name }})"}, report.Results[0].SQL)
+
+		// always rendered by the option vars=on
+		onPath := filepath.Join(tmpDir, "vars-on")
+		assert.Require(t, assert.NoErr(t, os.MkdirAll(onPath, 0755)))
+		assert.NoErr(t, os.WriteFile(filepath.Join(onPath, "20260105-102400-create-items.sql"), []byte(`-- Migrate-option: vars=on
+-- Migrate:UP
+CREATE TABLE ${prefix}items(id INTEGER);
+`), 0644))
+		_, err = newSQLiteRunner(t, dbPath, onPath).Up(ctx, command.UpOption{Yes: true, DryRun: true})
+		assert.ErrSubMsg(t, err, "undefined variable: prefix")
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		r := newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Vars = map[string]string{"prefix": "t1_"}
+
+		_, err := r.Up(ctx, command.UpOption{Yes: true, DryRun: true})
+		assert.ErrSubMsg(t, err, `map has no entry for key "name"`)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		r := newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Vars = map[string]string{"prefix": "t1_", "name": "N/A"}
+
+		report, err := r.Up(ctx, command.UpOption{DryRun: true})
+		assert.NoErr(t, err)
+		assert.True(t, report.DryRun)
+		assert.Eq(t, 1, report.Count(migration.ResultDryRun))
+		assert.Eq(t, []string{"CREATE TABLE t1_items(id INTEGER, name TEXT DEFAULT 'N/A')"}, report.Results[0].SQL)
+
+		// not executed
+		r = newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Vars = map[string]string{"prefix": "t1_", "name": "N/A"}
+		report, err = r.Up(ctx, command.UpOption{Yes: true})
+		assert.NoErr(t, err)
+		assert.Eq(t, 1, report.Count(migration.ResultApplied))
+	})
+
+	t.Run("rendered down", func(t *testing.T) {
+		r := newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Vars = map[string]string{"prefix": "t1_"}
+
+		report, err := r.Down(ctx, command.DownOption{Yes: true, Number: 1, DryRun: true})
+		assert.NoErr(t, err)
+		assert.Eq(t, []string{"DROP TABLE t1_items"}, report.Results[0].SQL)
+
+		r = newSQLiteRunner(t, dbPath, migPath)
+		r.Config().Vars = map[string]string{"prefix": "t1_"}
+		report, err = r.Down(ctx, command.DownOption{Yes: true, Number: 1})
+		assert.NoErr(t, err)
+		assert.Eq(t, 1, report.Count(migration.ResultRolled))
+	})
+}
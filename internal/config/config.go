@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"regexp"
@@ -52,10 +53,24 @@ type Migrations struct {
 	//  - allow use string-vars: {driver}
 	//  - allow use ENV-vars: ${APP_MODULE}
 	Path string `yaml:"path"`
-	// Table name for migration record table TODO
+	// Table name for migration record table. default: z_schema_migrations
+	//  - allow schema-qualified name, eg: ops.schema_migrations
+	//  - the meta and history tables are named by it: {table}_meta, {table}_history
 	Table string `yaml:"table"`
 	// Recursive search for migration SQL files. default: true
 	Recursive bool `yaml:"recursive"`
+	// VersionScheme for create and discover migration files. default: timestamp-local
+	//  - allow: timestamp-local, timestamp-utc, sequential
+	//  - custom time layout: timestamp-utc:20060102150405, digits of sequential: sequential:6
+	VersionScheme string `yaml:"version_scheme"`
+	// OutOfOrder policy for the pending migrations older than the newest applied migration. default: warn
+	//  - allow: run them silently
+	//  - warn: run them with warning
+	//  - error: refuse to run them, unless run up with --allow-out-of-order
+	OutOfOrder string `yaml:"out_of_order"`
+	// FS the file system to load migration files, eg: embed.FS.
+	// If is nil, will load from the OS file system.
+	FS fs.FS `yaml:"-" json:"-"`
 }
 
 // GetPaths get migration paths
@@ -68,6 +83,10 @@ type Config struct {
 	Verbose    bool       `yaml:"verbose"`
 	Database   Database   `yaml:"database"`
 	Migrations Migrations `yaml:"migrations"`
+	// Vars template variables for render the migration SQL. eg: ${schema}, {{ .Vars.owner }}
+	//
+	// can also be set by ENV: MIGRATIONS_VAR_SCHEMA=app will set var "schema"
+	Vars map[string]string `yaml:"vars"`
 
 	// ---- internal use  ----
 
@@ -135,6 +154,8 @@ func Load(configFile string) (*Config, error) {
 		return nil, err
 	}
 
+	setVarsFromENV(config)
+
 	// Validate db configuration
 	if err := checkDatabaseConfig(&config.Database); err != nil {
 		return nil, err
@@ -166,6 +187,9 @@ func initMigrationsConfig(migConfig *Migrations, fmtDriver string) {
 	if path := getEnvVal(EnvMigrationsPath); path != "" {
 		migConfig.Path = path
 	}
+	if table := getEnvVal(EnvMigrationsTable); table != "" {
+		migConfig.Table = table
+	}
 
 	// Set defaults if not defined
 	dirPath := migConfig.Path
@@ -203,16 +227,6 @@ func checkDatabaseConfig(dbCfg *Database) error {
 		if dbCfg.DSN == "" {
 			return fmt.Errorf("database DSN is required")
 		}
-	} else if fmtDriver == migcom.DriverMySQL {
-		// fix: must set multiStatements=true to DSN
-		if !strings.Contains(dbCfg.DSN, "multiStatements=true") {
-			if strings.Contains(dbCfg.DSN, "?") {
-				dbCfg.DSN += "&"
-			} else {
-				dbCfg.DSN += "?"
-			}
-			dbCfg.DSN += "multiStatements=true"
-		}
 	}
 	return nil
 }
@@ -293,16 +307,36 @@ const (
 	// EnvDBSqlDriver database driver for go sql/database package. default: DATABASE_DRIVER
 	EnvDBSqlDriver    = "DATABASE_SQL_DRIVER"
 	EnvMigrationsPath = "MIGRATIONS_PATH"
+	// EnvMigrationsTable table name of the migration records. eg: ops.schema_migrations
+	EnvMigrationsTable = "MIGRATIONS_TABLE"
 	// EnvDBURL database url. equals DATABASE_DRIVER + DATABASE_DSN
 	EnvDBURL = "DATABASE_URL"
 	// EnvPrefix prefix for environment variables
 	EnvPrefixKey = "MIGLITE_ENV_PREFIX"
+	// EnvVarPrefix prefix of the ENV for template variables. eg: MIGRATIONS_VAR_SCHEMA => schema
+	EnvVarPrefix = "MIGRATIONS_VAR_"
 )
 
 func getEnvVal(key string) string {
 	return os.Getenv(EnvPrefix + key)
 }
 
+// setVarsFromENV collect the template variables from ENV, they override the vars in config file.
+func setVarsFromENV(cfg *Config) {
+	prefix := EnvPrefix + EnvVarPrefix
+	for _, kv := range os.Environ() {
+		key, val, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+
+		if cfg.Vars == nil {
+			cfg.Vars = make(map[string]string)
+		}
+		cfg.Vars[strings.ToLower(key[len(prefix):])] = val
+	}
+}
+
 func setDBConfigFromENV(dbCfg *Database) error {
 	if dsn := getEnvVal(EnvDBDSN); dsn != "" {
 		dbCfg.DSN = dsn
@@ -363,9 +397,8 @@ func buildDSNFromConfig(dbCfg *Database) string {
 		if dbCfg.Port <= 0 {
 			dbCfg.Port = 3306
 		}
-		// fix: need set multiStatements=true for migration
 		return fmt.Sprintf(
-			"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local&multiStatements=true",
+			"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 			dbCfg.User, dbCfg.Password, dbCfg.Host, dbCfg.Port, dbCfg.DBName,
 		)
 	}
@@ -16,7 +16,7 @@ func TestLoadWithEnvFile(t *testing.T) {
 	customEnv := filepath.Join(tmpDir, "custom.env")
 
 	assert.NoErr(t, os.WriteFile(defaultEnv, []byte("DATABASE_URL=sqlite://default.db\n"), 0644))
-	assert.NoErr(t, os.WriteFile(customEnv, []byte("DATABASE_URL=sqlite://custom.db\nMIGRATIONS_PATH=./custom_migrations\n"), 0644))
+	assert.NoErr(t, os.WriteFile(customEnv, []byte("DATABASE_URL=sqlite://custom.db\nMIGRATIONS_PATH=./custom_migrations\nMIGRATIONS_TABLE=ops.schema_migrations\n"), 0644))
 
 	oldWd, err := os.Getwd()
 	assert.NoErr(t, err)
@@ -39,6 +39,7 @@ func TestLoadWithEnvFile(t *testing.T) {
 	assert.Eq(t, "sqlite", cfg.Database.Driver)
 	assert.Eq(t, "custom.db", cfg.Database.DSN)
 	assert.Eq(t, "./custom_migrations", cfg.Migrations.Path)
+	assert.Eq(t, "ops.schema_migrations", cfg.Migrations.Table)
 	assert.Eq(t, []string{customEnv}, envutil.LoadedEnvFiles())
 }
 
@@ -126,6 +127,34 @@ database:
 	assert.ErrMsg(t, err, "missing sqlite dsn")
 }
 
+func TestLoadVars(t *testing.T) {
+	clearConfigEnv(t)
+	configFile := filepath.Join(t.TempDir(), "miglite.yaml")
+	assert.NoErr(t, os.WriteFile(configFile, []byte(`
+database:
+  driver: sqlite
+  dsn: test.db
+vars:
+  schema: app
+  owner: ${APP_OWNER | admin}
+`), 0644))
+
+	t.Setenv(config.EnvVarPrefix+"SCHEMA", "tenant1")
+	t.Setenv(config.EnvVarPrefix+"TABLESPACE", "fast_ssd")
+	config.EnvPrefix = ""
+	config.EnvFile = ""
+	t.Cleanup(func() {
+		config.EnvPrefix = ""
+		config.EnvFile = ""
+	})
+
+	cfg, err := config.Load(configFile)
+	assert.NoErr(t, err)
+	assert.Eq(t, "tenant1", cfg.Vars["schema"])
+	assert.Eq(t, "admin", cfg.Vars["owner"])
+	assert.Eq(t, "fast_ssd", cfg.Vars["tablespace"])
+}
+
 func TestLoadUsesDefaultConfigFiles(t *testing.T) {
 	t.Run("loads miglite yaml first", func(t *testing.T) {
 		clearConfigEnv(t)
@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 
-	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/goutil/x/stdio"
 	"github.com/gookit/miglite/pkg/migcom"
 )
@@ -16,13 +15,20 @@ type DB struct {
 	// more information about the database
 	debug  bool
 	driver string // formatted driver name. eg migcom.DriverMySQL
+	// table name of the migration records. default is SchemaTableName
+	table string
+	logger   migcom.Logger
+	// external the sql.DB is supplied by the caller, it should not be closed by miglite.
+	external bool
 	// provider
 	provider SqlProvider
 }
 
-// NewWithSqlDB create a new database connection with sql.DB
+// NewWithSqlDB create a new database connection with sql.DB.
+//
+// NOTE: the sql.DB is owned by the caller, miglite will not close it.
 func NewWithSqlDB(driver string, db *sql.DB) *DB {
-	return &DB{DB: db, driver: driver}
+	return &DB{DB: db, driver: driver, table: SchemaTableName, external: true, logger: migcom.Log}
 }
 
 // NewDB create a new database connection. alias for Connect
@@ -52,23 +58,39 @@ func Connect(driver, sqlDriver, dsn string) (*DB, error) {
 	}
 
 	// db.SetMaxOpenConns(1) TODO support options
-	dbx := &DB{DB: db, driver: driver, dsn: dsn}
+	dbx := &DB{DB: db, driver: driver, dsn: dsn, table: SchemaTableName, logger: migcom.Log}
 	return dbx, nil
 }
 
 // Driver returns the formatted driver name
 func (db *DB) Driver() string { return db.driver }
 
+// IsExternal reports whether the sql.DB is supplied by the caller
+func (db *DB) IsExternal() bool { return db.external }
+
 // Close closes the database connection
 func (db *DB) Close() error { return db.DB.Close() }
 
 // SilentClose closes the database connection
 func (db *DB) SilentClose() {
 	if err := db.DB.Close(); err != nil {
-		ccolor.Errorln("[ERROR] database.Close:", err)
+		db.logger.Error("database.Close: %v", err)
+	}
+}
+
+// TableName returns the table name of the migration records
+func (db *DB) TableName() string { return db.table }
+
+// SetTableName sets the table name of the migration records
+func (db *DB) SetTableName(table string) {
+	if table != "" {
+		db.table = table
 	}
 }
 
+// SetLogger sets the logger
+func (db *DB) SetLogger(logger migcom.Logger) { db.logger = logger }
+
 // SetDebug sets the debug mode
 func (db *DB) SetDebug(debug bool) { db.debug = debug }
 
@@ -88,12 +110,14 @@ func (db *DB) InitSchema() error {
 		return err
 	}
 
-	var sqlStmt = provide.CreateSchema()
+	var sqlStmt = provide.CreateSchema(db.QuotedTableName())
 	if db.debug {
-		fmt.Println("[DEBUG] database.InitSchema:", sqlStmt)
+		db.logger.Debug("database.InitSchema: %s", sqlStmt)
+	}
+	if _, err = db.Exec(sqlStmt); err != nil {
+		return err
 	}
-	_, err = db.Exec(sqlStmt)
-	return err
+	return db.UpgradeSchema()
 }
 
 // DropSchema drops the migrations table
@@ -103,12 +127,13 @@ func (db *DB) DropSchema() error {
 		return err
 	}
 
-	var sqlStmt = provide.DropSchema()
-	if db.debug {
-		fmt.Println("[DEBUG] database.DropSchema:", sqlStmt)
+	// NOTE: the history table is kept, it is the append-only audit log
+	for _, table := range []string{db.table, db.MetaTableName()} {
+		if err = db.execSchema("DropSchema", provide.DropSchema(db.QuoteTable(table))); err != nil {
+			return err
+		}
 	}
-	_, err = db.Exec(sqlStmt)
-	return err
+	return nil
 }
 
 // ShowTables displays all tables in the database
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// SchemaVersion the latest version of the migrations table structure.
+// It is saved in the meta table, see DB.MetaTableName
+const SchemaVersion = 4
+
+// metaKeyVersion the meta key of the schema version
+const metaKeyVersion = "schema_version"
+
+// schemaColumn is a column added by upgrade the migrations table
+type schemaColumn struct {
+	Name string
+	// Type common column type, the provider will convert it. eg: VARCHAR(64) => NVARCHAR(64) for mssql
+	Type string
+}
+
+// schemaUpgrades the columns added by each schema version. version 1 is: version, applied_at, status
+var schemaUpgrades = []struct {
+	Version int
+	Columns []schemaColumn
+}{
+	{Version: 2, Columns: []schemaColumn{{"checksum", "VARCHAR(64)"}}},
+	{Version: 3, Columns: []schemaColumn{
+		{"execution_ms", "BIGINT"},
+		{"applied_by", "VARCHAR(160)"},
+		{"miglite_version", "VARCHAR(32)"},
+		{"description", "VARCHAR(255)"},
+	}},
+	{Version: 4, Columns: []schemaColumn{{"kind", "VARCHAR(16)"}}},
+}
+
+// QuotedTableName returns the table name of the migration records, quoted by the dialect. eg: "ops"."schema_migrations"
+func (db *DB) QuotedTableName() string { return db.QuoteTable(db.table) }
+
+// QuoteTable quotes the table name by the dialect of the driver, allow schema-qualified name.
+func (db *DB) QuoteTable(table string) string {
+	if provide, err := db.SqlProvider(); err == nil {
+		return provide.QuoteTable(table)
+	}
+	return table
+}
+
+// MetaTableName returns the meta table name of the migrations table. eg: z_schema_migrations_meta
+func (db *DB) MetaTableName() string { return db.table + "_meta" }
+
+// HistoryTableName returns the history table name of the migrations table. eg: z_schema_migrations_history
+func (db *DB) HistoryTableName() string { return db.table + "_history" }
+
+// SchemaVersion returns the structure version of the migrations table saved in the meta table.
+// Returns 0 if not saved, eg: the table is created by the old version.
+func (db *DB) SchemaVersion() (int, error) {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return 0, err
+	}
+
+	var value string
+	err = db.QueryRow(provide.QueryMeta(db.QuoteTable(db.MetaTableName())), metaKeyVersion).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to query schema version: %v", err)
+	}
+	return strconv.Atoi(value)
+}
+
+// TableExists checks the table exists in the database. table is not quoted, allow schema-qualified name.
+func (db *DB) TableExists(table string) (bool, error) {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err = db.QueryRow(provide.QueryTableExists(table)).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check table %s exists: %v", table, err)
+	}
+	return count > 0, nil
+}
+
+// CheckSchema checks the migrations table exists and is upgraded to the latest SchemaVersion,
+// it does not change the database. eg: for dry-run
+//
+// Returns false if the table does not exist, returns an error if the table needs to be upgraded.
+func (db *DB) CheckSchema() (bool, error) {
+	exists, err := db.TableExists(db.table)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	version := 0
+	if exists, err = db.TableExists(db.MetaTableName()); err != nil {
+		return false, err
+	}
+	if exists {
+		if version, err = db.SchemaVersion(); err != nil {
+			return false, err
+		}
+	}
+
+	if version < SchemaVersion {
+		return false, fmt.Errorf("the migrations table %s needs to be upgraded, run `miglite init` first", db.table)
+	}
+	return true, nil
+}
+
+// UpgradeSchema upgrades the migrations table created by the old version to the latest SchemaVersion,
+// and creates the meta and history tables if not exists.
+//
+// The upgrade is safe to run repeatedly or by multiple processes at the same time: the column is only added
+// when it does not exist, and the schema version is saved by upsert after all columns are added.
+func (db *DB) UpgradeSchema() error {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return err
+	}
+
+	metaTable := db.QuoteTable(db.MetaTableName())
+	if err = db.execSchema("UpgradeSchema", provide.CreateMetaSchema(metaTable)); err != nil {
+		return fmt.Errorf("failed to create meta table: %v", err)
+	}
+	if err = db.execSchema("UpgradeSchema", provide.CreateHistorySchema(db.QuoteTable(db.HistoryTableName()))); err != nil {
+		return fmt.Errorf("failed to create history table: %v", err)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil || version >= SchemaVersion {
+		return err
+	}
+
+	columns, err := db.columns()
+	if err != nil {
+		return err
+	}
+	for _, up := range schemaUpgrades {
+		if up.Version <= version {
+			continue
+		}
+		for _, col := range up.Columns {
+			if columns[col.Name] {
+				continue
+			}
+			if err = db.execSchema("UpgradeSchema", provide.AddColumn(db.QuotedTableName(), col.Name, col.Type)); err != nil {
+				// the column may be added by another process at the same time
+				if columns, err1 := db.columns(); err1 == nil && columns[col.Name] {
+					continue
+				}
+				return fmt.Errorf("failed to add column %s to %s: %v", col.Name, db.table, err)
+			}
+		}
+	}
+
+	// the repeatable migrations applied before schema version 4 have no kind
+	if version < 4 {
+		if err = db.fillRepeatableKind(); err != nil {
+			return err
+		}
+	}
+
+	// save the latest schema version, the InsertMeta is an upsert, can run by multiple processes at the same time
+	if _, err = db.Exec(provide.InsertMeta(metaTable), metaKeyVersion, strconv.Itoa(SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to save schema version: %v", err)
+	}
+	return nil
+}
+
+// fillRepeatableKind sets the kind of the repeatable migration records(R-xx.sql, R__xx.sql) which has no kind.
+// The versions are matched in Go, because LIKE is case-insensitive on some databases.
+func (db *DB) fillRepeatableKind() error {
+	rows, err := db.Query("SELECT version FROM " + db.QuotedTableName() + " WHERE kind IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to query records of %s: %v", db.table, err)
+	}
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err = rows.Scan(&version); err != nil {
+			stdio.SafeClose(rows)
+			return fmt.Errorf("failed to query records of %s: %v", db.table, err)
+		}
+		if strings.HasPrefix(version, "R-") || strings.HasPrefix(version, "R__") {
+			versions = append(versions, quoteString(version))
+		}
+	}
+	stdio.SafeClose(rows)
+	if err = rows.Err(); err != nil || len(versions) == 0 {
+		return err
+	}
+
+	sqlStmt := "UPDATE " + db.QuotedTableName() + " SET kind = 'repeatable' WHERE version IN (" + strings.Join(versions, ", ") + ")"
+	if _, err = db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("failed to update the kind of records in %s: %v", db.table, err)
+	}
+	return nil
+}
+
+// columns returns the column names of the migrations table
+func (db *DB) columns() (map[string]bool, error) {
+	rows, err := db.Query("SELECT * FROM " + db.QuotedTableName() + " WHERE 1 = 0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns of %s: %v", db.table, err)
+	}
+	defer stdio.SafeClose(rows)
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns of %s: %v", db.table, err)
+	}
+
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, nil
+}
+
+func (db *DB) execSchema(name, sqlStmt string) error {
+	if db.debug {
+		db.logger.Debug("database.%s: %s", name, sqlStmt)
+	}
+	_, err := db.Exec(sqlStmt)
+	return err
+}
@@ -2,13 +2,18 @@ package database
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/gookit/miglite/internal/migutil"
 )
 
-// SchemaTableName 默认数据库迁移记录表名
+// SchemaTableName 默认数据库迁移记录表名. 可以通过 DB.SetTableName 为每个连接单独设置
 var SchemaTableName = "z_schema_migrations"
 
+// RecordColumns 查询迁移记录的字段，与 QueryAll, QueryOne 的结果字段顺序一致
+const RecordColumns = "version, status, applied_at, checksum, execution_ms, applied_by, miglite_version, description, kind"
+
 // 内置SQL语句提供者适配
 var sqlProviders = map[string]SqlProvider{
 	"mssql":    &MSSqlProvider{},
@@ -36,29 +41,88 @@ func GetSqlProvider(driver string) (SqlProvider, error) {
 //   - ReSQL: mysql, postgres, sqlite3, oracle, mssql, ...
 //   - NoSQL: MongoDB, Redis, ElasticSearch, ...
 type SqlProvider interface {
-	// CreateSchema 创建数据库结构SQL
-	CreateSchema() string
-	DropSchema() string
+	// QuoteTable 按方言引用表名，允许带 schema 的表名. eg: ops.schema_migrations => "ops"."schema_migrations"
+	QuoteTable(table string) string
+	// CreateSchema 创建数据库结构SQL. params of methods: table 已引用的迁移记录表名, see QuoteTable
+	CreateSchema(table string) string
+	DropSchema(table string) string
 	ShowTables() string
+	// QueryTableExists 查询表是否存在，结果为 0 或 1. table 是未引用的表名，允许带 schema
+	QueryTableExists(table string) string
 	// QueryTableSchema 获取数据库表结构SQL
 	QueryTableSchema(tableName string) string
 
-	QueryAll() string
+	QueryAll(table string) string
 	// QueryOne by version. params: version
-	QueryOne() string
+	QueryOne(table string) string
 	// QueryStatus 获取指定版本状态 params: version
-	QueryStatus() string
+	QueryStatus(table string) string
 	// QueryExists 获取指定版本是否存在 params: version
-	QueryExists() string
+	QueryExists(table string) string
 	// InsertMigration 插入迁移记录 params: version, status
-	InsertMigration() string
+	InsertMigration(table string) string
 	// UpdateMigration 更新迁移记录 params: status, version
-	UpdateMigration() string
+	UpdateMigration(table string) string
 	// GetAppliedSortedByVersion 获取所有已迁移的版本，按迁移 version desc排序. params: status, limit
-	GetAppliedSortedByVersion() string
+	GetAppliedSortedByVersion(table string) string
+	// UpdateRecordInfo 更新迁移记录的详细信息
+	// params: checksum, execution_ms, applied_by, miglite_version, description, kind, version
+	UpdateRecordInfo(table string) string
+
+	// CreateMetaSchema 创建迁移记录表的元信息表，保存记录表结构的版本等. params of meta methods: metaTable 元信息表名
+	CreateMetaSchema(metaTable string) string
+	// QueryMeta 获取元信息 params: meta_key
+	QueryMeta(metaTable string) string
+	// InsertMeta 插入元信息，已存在时更新(upsert)，可以被多个进程同时执行. params: meta_key, meta_value
+	InsertMeta(metaTable string) string
+	// AddColumn 为旧版本创建的迁移记录表添加字段. colType 是通用类型，如 VARCHAR(64), BIGINT
+	AddColumn(table, column, colType string) string
+
+	// CreateHistorySchema 创建只追加的迁移历史表，记录每次 up, down, skip 和失败事件. params of history methods: historyTable 历史表名
+	CreateHistorySchema(historyTable string) string
+	// InsertHistory 插入历史事件 params: version, action, status, message, operator, execution_ms, created_at
+	InsertHistory(historyTable string) string
+	// QueryHistory 查询所有历史事件，按 id 排序
+	QueryHistory(historyTable string) string
 	// DeleteByVersion() string
 }
 
+// regexTableName 表名格式，允许带 schema 或数据库前缀. eg: schema_migrations, ops.schema_migrations, mydb.dbo.x
+var regexTableName = regexp.MustCompile(`^[A-Za-z_][\w$]*(\.[A-Za-z_][\w$]*){0,2}$`)
+
+// CheckTableName 检查迁移记录表名是否有效
+func CheckTableName(table string) error {
+	if !regexTableName.MatchString(table) {
+		return fmt.Errorf("invalid migrations table name %q, allow format: [schema.]table", table)
+	}
+	return nil
+}
+
+// quoteTable 引用表名的每个部分. eg: ops.schema_migrations => "ops"."schema_migrations"
+func quoteTable(table, open, end string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = open + strings.ReplaceAll(part, end, end+end) + end
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitTable 拆分带 schema 的表名. eg: ops.schema_migrations => ops, schema_migrations
+func splitTable(table string) (schema, name string) {
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
+
+// notRepeatable 排除可重复执行的迁移记录. 旧版本的记录 kind 为 NULL, 升级表结构时会补充 repeatable 记录的 kind
+const notRepeatable = "(kind IS NULL OR kind <> 'repeatable')"
+
+// quoteString 引用为 SQL 字符串，单引号会被转义为两个单引号
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 //
 // region Re-Sql Provider
 //
@@ -67,65 +131,136 @@ type SqlProvider interface {
 type ReSqlProvider struct{}
 
 // CreateSchema 创建数据库结构
-func (b *ReSqlProvider) CreateSchema() string {
-	return "CREATE TABLE IF NOT EXISTS " + SchemaTableName + ` (
+func (b *ReSqlProvider) CreateSchema(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + ` (
     version VARCHAR(160) PRIMARY KEY,
     applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    status VARCHAR(24) -- up,skip,down
+    status VARCHAR(24), -- up,skip,down
+    checksum VARCHAR(64),
+    execution_ms BIGINT,
+    applied_by VARCHAR(160),
+    miglite_version VARCHAR(32),
+    description VARCHAR(255),
+    kind VARCHAR(16) -- sql,go,repeatable
 );`
 }
 
 // DropSchema 删除数据库结构
-func (b *ReSqlProvider) DropSchema() string {
-	return "DROP TABLE IF EXISTS " + SchemaTableName
+func (b *ReSqlProvider) DropSchema(table string) string {
+	return "DROP TABLE IF EXISTS " + table
 }
 
+// QuoteTable 引用表名. mysql 使用反引号
+func (b *ReSqlProvider) QuoteTable(table string) string { return quoteTable(table, "`", "`") }
+
 // ShowTables 显示所有表
 func (b *ReSqlProvider) ShowTables() string { return "SHOW TABLES" }
 
+// QueryTableExists 查询表是否存在. mysql 的 schema 是数据库名，默认当前数据库
+func (b *ReSqlProvider) QueryTableExists(table string) string {
+	schema, name := splitTable(table)
+	schemaExpr := "DATABASE()"
+	if schema != "" {
+		schemaExpr = quoteString(schema)
+	}
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = " + schemaExpr + " AND table_name = " + quoteString(name)
+}
+
 // QueryTableSchema 获取数据库表结构
 func (b *ReSqlProvider) QueryTableSchema(tableName string) string {
 	return fmt.Sprintf("DESCRIBE `%s`", tableName)
 }
 
 // QueryAll 查询所有
-func (b *ReSqlProvider) QueryAll() string {
-	return "SELECT version, status, applied_at FROM " + SchemaTableName
+func (b *ReSqlProvider) QueryAll(table string) string {
+	return "SELECT " + RecordColumns + " FROM " + table
 }
 
 // QueryOne 获取指定版本
-func (b *ReSqlProvider) QueryOne() string {
-	return "SELECT version, status, applied_at FROM " + SchemaTableName + " WHERE version = ?"
+func (b *ReSqlProvider) QueryOne(table string) string {
+	return "SELECT " + RecordColumns + " FROM " + table + " WHERE version = ?"
 }
 
 // QueryStatus 查询指定版本状态
-func (b *ReSqlProvider) QueryStatus() string {
-	return "SELECT status FROM " + SchemaTableName + " WHERE version = ?"
+func (b *ReSqlProvider) QueryStatus(table string) string {
+	return "SELECT status FROM " + table + " WHERE version = ?"
 }
 
 // QueryExists 查询指定版本是否存在
-func (b *ReSqlProvider) QueryExists() string {
-	return "SELECT EXISTS(SELECT 1 FROM " + SchemaTableName + " WHERE version = ?)"
+func (b *ReSqlProvider) QueryExists(table string) string {
+	return "SELECT EXISTS(SELECT 1 FROM " + table + " WHERE version = ?)"
 }
 
 // DeleteByVersion 删除指定版本
-func (b *ReSqlProvider) DeleteByVersion() string {
-	return "DELETE FROM " + SchemaTableName + " WHERE version = ?"
+func (b *ReSqlProvider) DeleteByVersion(table string) string {
+	return "DELETE FROM " + table + " WHERE version = ?"
 }
 
 // InsertMigration 插入迁移记录
-func (b *ReSqlProvider) InsertMigration() string {
-	return "INSERT INTO " + SchemaTableName + " (version, status) VALUES (?, ?)"
+func (b *ReSqlProvider) InsertMigration(table string) string {
+	return "INSERT INTO " + table + " (version, status) VALUES (?, ?)"
 }
 
 // UpdateMigration 更新迁移记录
-func (b *ReSqlProvider) UpdateMigration() string {
-	return "UPDATE " + SchemaTableName + " SET applied_at = CURRENT_TIMESTAMP, status = ? WHERE version = ?"
+func (b *ReSqlProvider) UpdateMigration(table string) string {
+	return "UPDATE " + table + " SET applied_at = CURRENT_TIMESTAMP, status = ? WHERE version = ?"
 }
 
-// GetAppliedSortedByVersion 获取所有已迁移的版本，按迁移 version desc排序
-func (b *ReSqlProvider) GetAppliedSortedByVersion() string {
-	return "SELECT version, applied_at FROM " + SchemaTableName + " WHERE status=? ORDER BY version DESC LIMIT ?"
+// GetAppliedSortedByVersion 获取所有已迁移的版本，按迁移 version desc排序. 不包含可重复执行的迁移(kind=repeatable)
+func (b *ReSqlProvider) GetAppliedSortedByVersion(table string) string {
+	return "SELECT version, applied_at FROM " + table + " WHERE status=? AND " + notRepeatable + " ORDER BY version DESC LIMIT ?"
+}
+
+// UpdateRecordInfo 更新迁移记录的详细信息
+func (b *ReSqlProvider) UpdateRecordInfo(table string) string {
+	return "UPDATE " + table + " SET checksum = ?, execution_ms = ?, applied_by = ?, miglite_version = ?, description = ?, kind = ? WHERE version = ?"
+}
+
+// CreateMetaSchema 创建元信息表
+func (b *ReSqlProvider) CreateMetaSchema(metaTable string) string {
+	return "CREATE TABLE IF NOT EXISTS " + metaTable + ` (
+    meta_key VARCHAR(64) PRIMARY KEY,
+    meta_value VARCHAR(255)
+);`
+}
+
+// QueryMeta 获取元信息
+func (b *ReSqlProvider) QueryMeta(metaTable string) string {
+	return "SELECT meta_value FROM " + metaTable + " WHERE meta_key = ?"
+}
+
+// InsertMeta 插入或更新元信息. mysql 使用 ON DUPLICATE KEY UPDATE
+func (b *ReSqlProvider) InsertMeta(metaTable string) string {
+	return "INSERT INTO " + metaTable + " (meta_key, meta_value) VALUES (?, ?) ON DUPLICATE KEY UPDATE meta_value = VALUES(meta_value)"
+}
+
+// AddColumn 添加字段
+func (b *ReSqlProvider) AddColumn(table, column, colType string) string {
+	return "ALTER TABLE " + table + " ADD " + column + " " + colType
+}
+
+// CreateHistorySchema 创建迁移历史表
+func (b *ReSqlProvider) CreateHistorySchema(historyTable string) string {
+	return "CREATE TABLE IF NOT EXISTS " + historyTable + ` (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    version VARCHAR(160) NOT NULL,
+    action VARCHAR(24), -- up,down,skip
+    status VARCHAR(24), -- applied,rolled,skipped,failed,dirty
+    message TEXT,
+    operator VARCHAR(160),
+    execution_ms BIGINT,
+    created_at TIMESTAMP NULL
+);`
+}
+
+// InsertHistory 插入历史事件
+func (b *ReSqlProvider) InsertHistory(historyTable string) string {
+	return "INSERT INTO " + historyTable + " (version, action, status, message, operator, execution_ms, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+}
+
+// QueryHistory 查询所有历史事件
+func (b *ReSqlProvider) QueryHistory(historyTable string) string {
+	return "SELECT id, version, action, status, message, operator, execution_ms, created_at FROM " + historyTable + " ORDER BY id"
 }
 
 //
@@ -147,19 +282,57 @@ type SqliteProvider struct {
 }
 
 // CreateSchema 创建数据库结构. sqlite 时间字段是 DATETIME
-func (b *SqliteProvider) CreateSchema() string {
-	return "CREATE TABLE IF NOT EXISTS " + SchemaTableName + `(
+func (b *SqliteProvider) CreateSchema(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + `(
     version VARCHAR(160) PRIMARY KEY,
     applied_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    status VARCHAR(24) -- up,skip,down
+    status VARCHAR(24), -- up,skip,down
+    checksum VARCHAR(64),
+    execution_ms BIGINT,
+    applied_by VARCHAR(160),
+    miglite_version VARCHAR(32),
+    description VARCHAR(255),
+    kind VARCHAR(16) -- sql,go,repeatable
 );`
 }
 
+// CreateHistorySchema 创建迁移历史表. sqlite 使用 AUTOINCREMENT
+func (b *SqliteProvider) CreateHistorySchema(historyTable string) string {
+	return "CREATE TABLE IF NOT EXISTS " + historyTable + `(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version VARCHAR(160) NOT NULL,
+    action VARCHAR(24), -- up,down,skip
+    status VARCHAR(24), -- applied,rolled,skipped,failed,dirty
+    message TEXT,
+    operator VARCHAR(160),
+    execution_ms BIGINT,
+    created_at DATETIME
+);`
+}
+
+// InsertMeta 插入或更新元信息. sqlite 使用 ON CONFLICT
+func (b *SqliteProvider) InsertMeta(metaTable string) string {
+	return "INSERT INTO " + metaTable + " (meta_key, meta_value) VALUES (?, ?) ON CONFLICT (meta_key) DO UPDATE SET meta_value = excluded.meta_value"
+}
+
+// QuoteTable 引用表名. sqlite 使用双引号
+func (b *SqliteProvider) QuoteTable(table string) string { return quoteTable(table, `"`, `"`) }
+
 // ShowTables 显示所有表
 func (b *SqliteProvider) ShowTables() string {
 	return "SELECT name FROM sqlite_master WHERE type='table'"
 }
 
+// QueryTableExists 查询表是否存在. sqlite 的 schema 是附加的数据库名. eg: main
+func (b *SqliteProvider) QueryTableExists(table string) string {
+	schema, name := splitTable(table)
+	master := "sqlite_master"
+	if schema != "" {
+		master = b.QuoteTable(schema) + "." + master
+	}
+	return "SELECT COUNT(*) FROM " + master + " WHERE type = 'table' AND name = " + quoteString(name)
+}
+
 // QueryTableSchema 获取数据库表结构
 func (b *SqliteProvider) QueryTableSchema(tableName string) string {
 	return fmt.Sprintf("PRAGMA table_info(`%s`)", tableName)
@@ -170,19 +343,123 @@ func (b *SqliteProvider) QueryTableSchema(tableName string) string {
 //
 
 // MSSqlProvider for mssql
+//
+// NOTE: mssql 绑定参数使用 @pN, 不支持 LIMIT 和 SELECT EXISTS(...)
 type MSSqlProvider struct {
 	ReSqlProvider
 }
 
-// CreateSchema 创建数据库结构. mssql 使用 DATETIME2 和 IDENTITY
-func (b *MSSqlProvider) CreateSchema() string {
-	return "CREATE TABLE " + SchemaTableName + `(
+// QueryOne 获取指定版本
+func (b *MSSqlProvider) QueryOne(table string) string {
+	return "SELECT " + RecordColumns + " FROM " + table + " WHERE version = @p1"
+}
+
+// QueryStatus 查询指定版本状态
+func (b *MSSqlProvider) QueryStatus(table string) string {
+	return "SELECT status FROM " + table + " WHERE version = @p1"
+}
+
+// QueryExists 查询指定版本是否存在. mssql 不支持 SELECT EXISTS(...)
+func (b *MSSqlProvider) QueryExists(table string) string {
+	return "SELECT CASE WHEN EXISTS(SELECT 1 FROM " + table + " WHERE version = @p1) THEN 1 ELSE 0 END"
+}
+
+// DeleteByVersion 删除指定版本
+func (b *MSSqlProvider) DeleteByVersion(table string) string {
+	return "DELETE FROM " + table + " WHERE version = @p1"
+}
+
+// InsertMigration 插入迁移记录
+func (b *MSSqlProvider) InsertMigration(table string) string {
+	return "INSERT INTO " + table + " (version, status) VALUES (@p1, @p2)"
+}
+
+// UpdateMigration 更新迁移记录
+func (b *MSSqlProvider) UpdateMigration(table string) string {
+	return "UPDATE " + table + " SET applied_at = CURRENT_TIMESTAMP, status = @p1 WHERE version = @p2"
+}
+
+// GetAppliedSortedByVersion 获取所有已迁移的版本，按迁移 version desc排序. mssql 使用 TOP 代替 LIMIT
+func (b *MSSqlProvider) GetAppliedSortedByVersion(table string) string {
+	return "SELECT TOP (@p2) version, applied_at FROM " + table + " WHERE status = @p1 AND " + notRepeatable + " ORDER BY version DESC"
+}
+
+// UpdateRecordInfo 更新迁移记录的详细信息
+func (b *MSSqlProvider) UpdateRecordInfo(table string) string {
+	return "UPDATE " + table + " SET checksum = @p1, execution_ms = @p2, applied_by = @p3, miglite_version = @p4, description = @p5, kind = @p6 WHERE version = @p7"
+}
+
+// QueryMeta 获取元信息
+func (b *MSSqlProvider) QueryMeta(metaTable string) string {
+	return "SELECT meta_value FROM " + metaTable + " WHERE meta_key = @p1"
+}
+
+// InsertMeta 插入或更新元信息. mssql 使用 MERGE, HOLDLOCK 避免并发时插入重复的 key
+func (b *MSSqlProvider) InsertMeta(metaTable string) string {
+	return "MERGE INTO " + metaTable + ` WITH (HOLDLOCK) AS t
+USING (SELECT @p1 AS meta_key, @p2 AS meta_value) AS s ON t.meta_key = s.meta_key
+WHEN MATCHED THEN UPDATE SET meta_value = s.meta_value
+WHEN NOT MATCHED THEN INSERT (meta_key, meta_value) VALUES (s.meta_key, s.meta_value);`
+}
+
+// InsertHistory 插入历史事件
+func (b *MSSqlProvider) InsertHistory(historyTable string) string {
+	return "INSERT INTO " + historyTable + " (version, action, status, message, operator, execution_ms, created_at) VALUES (@p1, @p2, @p3, @p4, @p5, @p6, @p7)"
+}
+
+// CreateSchema 创建数据库结构. mssql 使用 DATETIME2, 不支持 IF NOT EXISTS
+func (b *MSSqlProvider) CreateSchema(table string) string {
+	return "IF OBJECT_ID(N'" + table + "', N'U') IS NULL CREATE TABLE " + table + `(
     version NVARCHAR(160) NOT NULL PRIMARY KEY,
     applied_at DATETIME2 DEFAULT CURRENT_TIMESTAMP,
-    status NVARCHAR(24) -- up,skip,down
+    status NVARCHAR(24), -- up,skip,down
+    checksum NVARCHAR(64),
+    execution_ms BIGINT,
+    applied_by NVARCHAR(160),
+    miglite_version NVARCHAR(32),
+    description NVARCHAR(255),
+    kind NVARCHAR(16) -- sql,go,repeatable
 );`
 }
 
+// CreateMetaSchema 创建元信息表
+func (b *MSSqlProvider) CreateMetaSchema(metaTable string) string {
+	return "IF OBJECT_ID(N'" + metaTable + "', N'U') IS NULL CREATE TABLE " + metaTable + `(
+    meta_key NVARCHAR(64) NOT NULL PRIMARY KEY,
+    meta_value NVARCHAR(255)
+);`
+}
+
+// CreateHistorySchema 创建迁移历史表. mssql 使用 IDENTITY
+func (b *MSSqlProvider) CreateHistorySchema(historyTable string) string {
+	return "IF OBJECT_ID(N'" + historyTable + "', N'U') IS NULL CREATE TABLE " + historyTable + `(
+    id BIGINT IDENTITY(1,1) PRIMARY KEY,
+    version NVARCHAR(160) NOT NULL,
+    action NVARCHAR(24), -- up,down,skip
+    status NVARCHAR(24), -- applied,rolled,skipped,failed,dirty
+    message NVARCHAR(MAX),
+    operator NVARCHAR(160),
+    execution_ms BIGINT,
+    created_at DATETIME2
+);`
+}
+
+// AddColumn 添加字段. mssql 使用 NVARCHAR
+func (b *MSSqlProvider) AddColumn(table, column, colType string) string {
+	if strings.HasPrefix(colType, "VARCHAR") {
+		colType = "N" + colType
+	}
+	return "ALTER TABLE " + table + " ADD " + column + " " + colType
+}
+
+// QuoteTable 引用表名. mssql 使用方括号
+func (b *MSSqlProvider) QuoteTable(table string) string { return quoteTable(table, "[", "]") }
+
+// QueryTableExists 查询表是否存在. mssql 使用 OBJECT_ID
+func (b *MSSqlProvider) QueryTableExists(table string) string {
+	return "SELECT CASE WHEN OBJECT_ID(N" + quoteString(b.QuoteTable(table)) + ", N'U') IS NULL THEN 0 ELSE 1 END"
+}
+
 // ShowTables 显示所有表
 func (b *MSSqlProvider) ShowTables() string {
 	return `SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE'`
@@ -210,6 +487,19 @@ type PgSqlProvider struct {
 	ReSqlProvider
 }
 
+// QuoteTable 引用表名. pgsql 使用双引号
+//
+// NOTE: pgsql 会把未引用的标识符转为小写，因此先转为小写再引用，与未引用时使用同一个表.
+// eg: ops.SchemaMigrations => "ops"."schemamigrations"
+func (b *PgSqlProvider) QuoteTable(table string) string {
+	return quoteTable(strings.ToLower(table), `"`, `"`)
+}
+
+// QueryTableExists 查询表是否存在. pgsql 使用 to_regclass，未带 schema 时按 search_path 查找
+func (b *PgSqlProvider) QueryTableExists(table string) string {
+	return "SELECT CASE WHEN to_regclass(" + quoteString(b.QuoteTable(table)) + ") IS NULL THEN 0 ELSE 1 END"
+}
+
 // ShowTables 显示所有表
 func (b *PgSqlProvider) ShowTables() string {
 	return `SELECT tablename FROM pg_tables WHERE schemaname = 'public'`
@@ -225,36 +515,70 @@ ORDER BY ordinal_position`, tableName)
 }
 
 // QueryOne 获取指定版本
-func (b *PgSqlProvider) QueryOne() string {
-	return "SELECT version, status, applied_at FROM " + SchemaTableName + " WHERE version = $1"
+func (b *PgSqlProvider) QueryOne(table string) string {
+	return "SELECT " + RecordColumns + " FROM " + table + " WHERE version = $1"
 }
 
 // QueryStatus 查询指定版本状态
-func (b *PgSqlProvider) QueryStatus() string {
-	return "SELECT status FROM " + SchemaTableName + " WHERE version = $1"
+func (b *PgSqlProvider) QueryStatus(table string) string {
+	return "SELECT status FROM " + table + " WHERE version = $1"
 }
 
 // QueryExists 获取指定版本是否存在
-func (b *PgSqlProvider) QueryExists() string {
-	return "SELECT EXISTS(SELECT 1 FROM " + SchemaTableName + " WHERE version = $1)"
+func (b *PgSqlProvider) QueryExists(table string) string {
+	return "SELECT EXISTS(SELECT 1 FROM " + table + " WHERE version = $1)"
 }
 
 // DeleteByVersion 删除指定版本
-func (b *PgSqlProvider) DeleteByVersion() string {
-	return "DELETE FROM " + SchemaTableName + " WHERE version = $1"
+func (b *PgSqlProvider) DeleteByVersion(table string) string {
+	return "DELETE FROM " + table + " WHERE version = $1"
 }
 
 // InsertMigration 插入迁移记录
-func (b *PgSqlProvider) InsertMigration() string {
-	return "INSERT INTO " + SchemaTableName + " (version, status) VALUES ($1, $2)"
+func (b *PgSqlProvider) InsertMigration(table string) string {
+	return "INSERT INTO " + table + " (version, status) VALUES ($1, $2)"
 }
 
 // UpdateMigration 插入迁移记录
-func (b *PgSqlProvider) UpdateMigration() string {
-	return "UPDATE " + SchemaTableName + " SET applied_at = CURRENT_TIMESTAMP, status = $1 WHERE version = $2"
+func (b *PgSqlProvider) UpdateMigration(table string) string {
+	return "UPDATE " + table + " SET applied_at = CURRENT_TIMESTAMP, status = $1 WHERE version = $2"
 }
 
 // GetAppliedSortedByVersion 获取所有已迁移的版本，按迁移 version desc排序
-func (b *PgSqlProvider) GetAppliedSortedByVersion() string {
-	return "SELECT version, applied_at FROM " + SchemaTableName + " WHERE status=$1 ORDER BY version DESC LIMIT $2"
+func (b *PgSqlProvider) GetAppliedSortedByVersion(table string) string {
+	return "SELECT version, applied_at FROM " + table + " WHERE status=$1 AND " + notRepeatable + " ORDER BY version DESC LIMIT $2"
+}
+
+// UpdateRecordInfo 更新迁移记录的详细信息
+func (b *PgSqlProvider) UpdateRecordInfo(table string) string {
+	return "UPDATE " + table + " SET checksum = $1, execution_ms = $2, applied_by = $3, miglite_version = $4, description = $5, kind = $6 WHERE version = $7"
+}
+
+// CreateHistorySchema 创建迁移历史表. pgsql 使用 BIGSERIAL
+func (b *PgSqlProvider) CreateHistorySchema(historyTable string) string {
+	return "CREATE TABLE IF NOT EXISTS " + historyTable + ` (
+    id BIGSERIAL PRIMARY KEY,
+    version VARCHAR(160) NOT NULL,
+    action VARCHAR(24), -- up,down,skip
+    status VARCHAR(24), -- applied,rolled,skipped,failed,dirty
+    message TEXT,
+    operator VARCHAR(160),
+    execution_ms BIGINT,
+    created_at TIMESTAMP
+);`
+}
+
+// InsertHistory 插入历史事件
+func (b *PgSqlProvider) InsertHistory(historyTable string) string {
+	return "INSERT INTO " + historyTable + " (version, action, status, message, operator, execution_ms, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+}
+
+// QueryMeta 获取元信息
+func (b *PgSqlProvider) QueryMeta(metaTable string) string {
+	return "SELECT meta_value FROM " + metaTable + " WHERE meta_key = $1"
+}
+
+// InsertMeta 插入或更新元信息. pgsql 使用 ON CONFLICT
+func (b *PgSqlProvider) InsertMeta(metaTable string) string {
+	return "INSERT INTO " + metaTable + " (meta_key, meta_value) VALUES ($1, $2) ON CONFLICT (meta_key) DO UPDATE SET meta_value = excluded.meta_value"
 }
@@ -1,8 +1,12 @@
 package miglite
 
 import (
+	"io/fs"
+
 	"github.com/gookit/miglite/internal/config"
 	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
 )
 
 // Config is the configuration struct for the Migrator
@@ -11,15 +15,50 @@ type Config = config.Config
 // ConfigFn is a function type for updating the configuration
 type ConfigFn func(c *Config)
 
+// WithFS sets the file system to load migration files, eg: embed.FS
+//
+// Example:
+//
+//	//go:embed migrations
+//	var migrationsFS embed.FS
+//
+//	mig, err := miglite.NewAuto(miglite.WithFS(migrationsFS))
+func WithFS(fsys fs.FS) ConfigFn {
+	return func(c *Config) {
+		c.Migrations.FS = fsys
+	}
+}
+
 // SetEnvPrefix set environment prefix
 func SetEnvPrefix(prefix string) {
 	config.EnvPrefix = prefix
 }
 
-// SqlProvider is the interface for database provider
+// RunReport is the report of run migrations
+type RunReport = migration.RunReport
+
+// Logger interface for output messages. see migcom.NewSlogLogger, migcom.NopLogger
+type Logger = migcom.Logger
+
+// Hook for the lifecycle of run migrations
+type Hook = migration.Hook
+
+// NopHook is a Hook that does nothing, embed it to implement only the needed methods.
+type NopHook = migration.NopHook
+
+// SqlProvider is the interface for database provider.
+//
+// NOTE: since the migrations table name can be set for each connection, every method of it takes
+// the quoted table name as parameter, the implementations for the old method set must be updated.
+// Embed ReSqlProvider to implement it and override only the needed methods.
 type SqlProvider = database.SqlProvider
 
-// SetSchemaTableName set schema table name
+// ReSqlProvider is the common SqlProvider for the relational databases
+type ReSqlProvider = database.ReSqlProvider
+
+// SetSchemaTableName set the default schema table name for new connections.
+//
+// Deprecated: it does not affect the Migrator or DB already created. Use Migrator.SetTableName instead.
 func SetSchemaTableName(tableName string) {
 	database.SchemaTableName = tableName
 }
@@ -28,3 +67,13 @@ func SetSchemaTableName(tableName string) {
 func AddSqlProvider(driver string, provider SqlProvider) {
 	database.AddProvider(driver, provider)
 }
+
+// MigrateFunc is the function for run a Go-code migration in the transaction
+type MigrateFunc = migration.MigrateFunc
+
+// Register adds a Go-code migration to the global registry. Usually call it on init().
+//
+// NOTE: will panic on invalid or duplicate version.
+func Register(version string, up, down MigrateFunc, fns ...migration.MigrationFn) {
+	migration.Register(version, up, down, fns...)
+}
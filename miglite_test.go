@@ -1,10 +1,13 @@
 package miglite_test
 
 import (
+	"fmt"
+
 	"github.com/gookit/goutil"
 	"github.com/gookit/miglite"
 	"github.com/gookit/miglite/internal/config"
 	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migration"
 )
 
 func ExampleNew() {
@@ -14,11 +17,12 @@ func ExampleNew() {
 	goutil.PanicIfErr(err) // handle error
 
 	// run up migrations
-	err = mig.Up(command.UpOption{
+	report, err := mig.Up(command.UpOption{
 		Yes: true, // dont confirm
 		// ... options
 	})
 	goutil.PanicIfErr(err) // handle error
+	fmt.Println("applied:", report.Count(migration.ResultApplied))
 
 	// other operations
 
@@ -29,22 +33,25 @@ func ExampleNew() {
 	goutil.PanicIfErr(err) // handle error
 
 	// run down migrations
-	err = mig.Down(command.DownOption{
+	_, err = mig.Down(command.DownOption{
 		// ... options
 	})
 	goutil.PanicIfErr(err)
 
-	err = mig.Status(command.StatusOption{
+	status, err := mig.Status(command.StatusOption{
 		// ... options
 	})
 	goutil.PanicIfErr(err) // handle error
+	fmt.Println("migrations:", len(status.Statuses))
 
-	err = mig.Show(command.ShowOption{
+	res, err := mig.Show(command.ShowOption{
+		Tables: true,
 		// ... options
 	})
 	goutil.PanicIfErr(err) // handle error
+	fmt.Println("tables:", res.Tables)
 
-	err = mig.Skip(command.SkipOption{
+	_, err = mig.Skip(command.SkipOption{
 		// ... options
 	})
 	goutil.PanicIfErr(err) // handle error
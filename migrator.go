@@ -1,22 +1,21 @@
 package miglite
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/gookit/miglite/internal/config"
 	"github.com/gookit/miglite/internal/database"
 	"github.com/gookit/miglite/pkg/command"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
 )
 
 // Migrator manage the migration
 type Migrator struct {
 	cfg *Config
-	// TODO add migrations by go code
-	//
-	// Example:
-	// 	mig.Add("2026...-add_user_table", `UP sql`, `DOWN sql`, options)
-	//
-	// migrations []*migration.Migration
+	// runner owns the database connection, table name and logger of the Migrator
+	runner *command.Runner
 }
 
 // NewAuto creates a new Migrator instance with autoload default config
@@ -41,42 +40,144 @@ func New(configFile string, fns ...ConfigFn) (*Migrator, error) {
 
 // NewWithConfig creates a new Migrator instance with a pre-configured Config
 func NewWithConfig(cfg *Config) *Migrator {
-	command.SetCfg(cfg)
-	return &Migrator{cfg: cfg}
+	return &Migrator{cfg: cfg, runner: command.NewRunner(cfg)}
 }
 
-// SetSqlDB sets the database connection
+// Config returns the config of the Migrator
+func (m *Migrator) Config() *Config { return m.cfg }
+
+// SetRenderer sets the renderer for render the progress of run migrations.
+//
+// Example:
+//
+//	mig.SetRenderer(command.NewConsoleRenderer(false))
+func (m *Migrator) SetRenderer(r command.Renderer) {
+	m.runner.SetRenderer(r)
+}
+
+// SetLogger sets the logger of the Migrator. default is migcom.Log
+//
+// Example:
+//
+//	// output JSON logs by slog
+//	mig.SetLogger(migcom.NewSlogLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))))
+//	// silent all messages
+//	mig.SetLogger(migcom.NopLogger{})
+func (m *Migrator) SetLogger(logger migcom.Logger) {
+	m.runner.SetLogger(logger)
+}
+
+// AddHook adds hooks for the lifecycle of run migrations. see migration.Hook
+//
+// Example:
+//
+//	type auditHook struct{ miglite.NopHook }
+//
+//	func (auditHook) AfterMigration(ctx context.Context, mig *migration.Migration, direction string, tx *sql.Tx) error {
+//		_, err := tx.ExecContext(ctx, "INSERT INTO audit_logs(event) VALUES (?)", direction+":"+mig.Version)
+//		return err
+//	}
+//
+//	mig.AddHook(auditHook{})
+func (m *Migrator) AddHook(hooks ...Hook) {
+	m.runner.AddHook(hooks...)
+}
+
+// SetTableName sets the table name of the migration records. default is "z_schema_migrations"
+func (m *Migrator) SetTableName(tableName string) {
+	m.runner.SetTableName(tableName)
+}
+
+// SetVar sets a template variable for render the migration SQL. eg: ${schema}, {{ .Vars.owner }}
+func (m *Migrator) SetVar(name, value string) {
+	if m.cfg.Vars == nil {
+		m.cfg.Vars = make(map[string]string)
+	}
+	m.cfg.Vars[name] = value
+}
+
+// SetSqlDB sets the database connection. The db is owned by the caller,
+// it will not be closed by the Migrator and can be reused by multiple operations.
 func (m *Migrator) SetSqlDB(db *sql.DB) {
 	dbCfg := m.cfg.Database
-	command.SetDB(database.NewWithSqlDB(dbCfg.Driver, db))
+	m.runner.SetDB(database.NewWithSqlDB(dbCfg.Driver, db))
+}
+
+// Add adds a Go-code migration, it will be merged with the migration files by version.
+//
+//   - version: format is YYYYMMDD-NNNNNN-{name}
+//   - down: allow be nil, if the migration is not reversible.
+//
+// Example:
+//
+//	mig.Add("20260105-102400-fill-user-age", func(ctx context.Context, tx *sql.Tx) error {
+//		_, err := tx.ExecContext(ctx, "UPDATE users SET age = 18 WHERE age IS NULL")
+//		return err
+//	}, nil)
+func (m *Migrator) Add(version string, up, down MigrateFunc, fns ...migration.MigrationFn) error {
+	mig, err := migration.NewGoMigration(version, up, down, fns...)
+	if err != nil {
+		return err
+	}
+
+	m.runner.AddMigrations(mig)
+	return nil
 }
 
 // Init initializes the migration schema
 func (m *Migrator) Init(opt command.InitOption) error {
-	return command.HandleInit(opt)
+	return m.runner.Init(opt)
+}
+
+// Up runs the migration up operation, returns the run report.
+func (m *Migrator) Up(opt command.UpOption) (*RunReport, error) {
+	return m.UpContext(context.Background(), opt)
+}
+
+// UpContext runs the migration up operation with context, returns the run report.
+//
+// If the context is canceled, the migration in progress will be rolled back and stop run the remaining.
+func (m *Migrator) UpContext(ctx context.Context, opt command.UpOption) (*RunReport, error) {
+	return m.runner.Up(ctx, opt)
+}
+
+// Down runs the migration down operation, returns the run report.
+func (m *Migrator) Down(opt command.DownOption) (*RunReport, error) {
+	return m.DownContext(context.Background(), opt)
+}
+
+// DownContext runs the migration down operation with context, returns the run report.
+func (m *Migrator) DownContext(ctx context.Context, opt command.DownOption) (*RunReport, error) {
+	return m.runner.Down(ctx, opt)
+}
+
+// Skip skips some migration files, returns the run report.
+func (m *Migrator) Skip(opt command.SkipOption) (*RunReport, error) {
+	return m.SkipContext(context.Background(), opt)
 }
 
-// Up runs the migration up operation.
-func (m *Migrator) Up(opt command.UpOption) error {
-	return command.HandleUp(opt)
+// SkipContext skips some migration files with context, returns the run report.
+func (m *Migrator) SkipContext(ctx context.Context, opt command.SkipOption) (*RunReport, error) {
+	return m.runner.Skip(ctx, opt)
 }
 
-// Down runs the migration down operation.
-func (m *Migrator) Down(opt command.DownOption) error {
-	return command.HandleDown(opt)
+// Status returns the status of the migrations.
+func (m *Migrator) Status(opt command.StatusOption) (*command.StatusReport, error) {
+	return m.runner.Status(opt)
 }
 
-// Skip skips some migration files.
-func (m *Migrator) Skip(opt command.SkipOption) error {
-	return command.HandleSkip(opt)
+// Validate checks the applied migrations whose file has been changed or deleted.
+// If found any drift, will return an error.
+func (m *Migrator) Validate(opt command.ValidateOption) ([]migration.Drift, error) {
+	return m.runner.Validate(opt)
 }
 
-// Status shows the status of the migrations.
-func (m *Migrator) Status(opt command.StatusOption) error {
-	return command.HandleStatus(opt)
+// History returns the up, down, skip and failure events of migrations, filtered by the options.
+func (m *Migrator) History(opt command.HistoryOption) ([]migration.HistoryEvent, error) {
+	return m.runner.History(opt)
 }
 
-// Show displays all tables in the database.
-func (m *Migrator) Show(opt command.ShowOption) error {
-	return command.HandleShow(opt)
+// Show returns the tables in the database, or the columns of a table.
+func (m *Migrator) Show(opt command.ShowOption) (*command.ShowResult, error) {
+	return m.runner.Show(opt)
 }
@@ -3,6 +3,7 @@ package command
 import (
 	"strings"
 
+	"github.com/gookit/goutil/cflag"
 	"github.com/gookit/goutil/cflag/capp"
 	"github.com/gookit/goutil/strutil"
 	"github.com/gookit/goutil/x/ccolor"
@@ -36,6 +37,8 @@ var (
 	ConfigFile string
 	// DBName overrides the configured database name.
 	DBName string
+	// Vars template variables from the CLI option: --var k=v, override the config vars.
+	Vars = cflag.NewKVString()
 )
 
 func bindCommonFlags(c *capp.Cmd) {
@@ -46,6 +49,11 @@ func bindCommonFlags(c *capp.Cmd) {
 	c.StringVar(&DBName, "db", "", "Override the configured database name")
 }
 
+// bindVarFlag binds the --var option for render the migration SQL
+func bindVarFlag(c *capp.Cmd) {
+	c.Var(&Vars, "var", "Set template variable for migration SQL, allow multi. eg: <mga>--var schema=app</>")
+}
+
 // NewApp creates a new CLI application
 func NewApp(name, version, description string) *capp.App {
 	app := capp.NewWith(name, version, description)
@@ -66,8 +74,11 @@ func NewApp(name, version, description string) *capp.App {
 		DownCommand(),
 		SkipCommand(),
 		StatusCommand(),
+		NewValidateCommand(),
+		NewHistoryCommand(),
 		NewExecCommand(),
 		NewShowCommand(),
+		NewImportCommand(),
 	)
 
 	app.OnAppFlagParsed = beforeRun
@@ -126,16 +126,15 @@ func TestInitLoadConfigDBOverride(t *testing.T) {
 	envutil.StdDotenv().Reset()
 	config.EnvPrefix = ""
 	config.EnvFile = ""
-	cfg = nil
 	DBName = "new.db"
 	t.Cleanup(func() {
 		envutil.StdDotenv().Reset()
 		config.EnvPrefix = ""
 		config.EnvFile = ""
-		cfg = nil
 		DBName = ""
 	})
 
-	assert.NoErr(t, initLoadConfig())
-	assert.Eq(t, "new.db", Cfg().Database.DSN)
+	cfg, err := loadCliConfig()
+	assert.NoErr(t, err)
+	assert.Eq(t, "new.db", cfg.Database.DSN)
 }
@@ -1,15 +1,18 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gookit/goutil/dump"
 	"github.com/gookit/goutil/envutil"
 	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/miglite/internal/config"
-	"github.com/gookit/miglite/internal/database"
-	"github.com/gookit/miglite/pkg/migration"
+	"github.com/gookit/miglite/pkg/migcom"
 )
 
 const TimeLayout = "2006-01-02 15:04:05"
@@ -19,46 +22,31 @@ var OnConfigLoaded = func(cfg *config.Config) error {
 	return nil
 }
 
-// cache for testing
-var cfg *config.Config
-var db *database.DB
-
-// Cfg get config instance
-func Cfg() *config.Config { return cfg }
-
-// SetCfg set config instance. use on manual run logic.
-func SetCfg(c *config.Config) {
-	cfg = c
-	ConfigFile = c.ConfigFile
-	ShowVerbose = c.Verbose
-}
-
-// DB get database instance
-func DB() *database.DB { return db }
-
-// SetDB set database instance. use on manual run logic.
-func SetDB(d *database.DB) { db = d }
-
-func initLoadConfig() error {
-	if cfg != nil {
-		return nil
-	}
+// loadCliConfig loads the configuration by the CLI options
+func loadCliConfig() (*config.Config, error) {
 	syncEnvOptions()
 
 	// Load configuration
-	var err error
-	cfg, err = config.Load(ConfigFile)
+	cfg, err := config.Load(ConfigFile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+		return nil, fmt.Errorf("failed to load config: %v", err)
 	}
 	if err = config.OverrideDBName(&cfg.Database, DBName); err != nil {
-		return fmt.Errorf("failed to override database name: %v", err)
+		return nil, fmt.Errorf("failed to override database name: %v", err)
+	}
+	if vars := Vars.Data(); len(vars) > 0 {
+		if cfg.Vars == nil {
+			cfg.Vars = make(map[string]string, len(vars))
+		}
+		for k, v := range vars {
+			cfg.Vars[k] = v
+		}
 	}
 
 	// fire OnConfigLoaded hook
 	if OnConfigLoaded != nil {
 		if err = OnConfigLoaded(cfg); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -72,27 +60,26 @@ func initLoadConfig() error {
 		dump.NoLoc(cfg)
 	}
 
-	return nil
+	return cfg, nil
 }
 
-func initConfigAndDB() (err error) {
-	// Load configuration
-	if err = initLoadConfig(); err != nil {
-		return err
+// newCliRunner creates a Runner for the CLI commands, it renders the progress to console.
+func newCliRunner() (*Runner, error) {
+	cfg, err := loadCliConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	// Connect to database
-	if db == nil {
-		dbCfg := cfg.Database
-		db, err = database.NewDB(dbCfg.Driver, dbCfg.SqlDriver, dbCfg.DSN)
-		if err != nil {
-			return fmt.Errorf("failed to connect to database: %v", err)
-		}
-		ccolor.Printf("✅  Database connect successful! driver: <green>%s</>\n", db.Driver())
-	}
+	r := NewRunner(cfg)
+	r.SetLogger(&migcom.ConsoleLogger{})
+	r.SetVerbose(ShowVerbose || cfg.Verbose)
+	r.SetRenderer(NewConsoleRenderer(r.verbose))
+	return r, nil
+}
 
-	db.SetDebug(ShowVerbose)
-	return nil
+// signalContext returns a context that is canceled on receive SIGINT or SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 }
 
 func formatTime(t time.Time) string {
@@ -101,7 +88,3 @@ func formatTime(t time.Time) string {
 	}
 	return t.Format(TimeLayout)
 }
-
-func findMigrations() ([]*migration.Migration, error) {
-	return migration.FindMigrations(cfg.Migrations.Path, cfg.Migrations.Recursive)
-}
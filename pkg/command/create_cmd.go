@@ -7,7 +7,6 @@ import (
 	"github.com/gookit/goutil/cflag/capp"
 	"github.com/gookit/goutil/cliutil"
 	"github.com/gookit/goutil/x/ccolor"
-	"github.com/gookit/miglite/pkg/migration"
 )
 
 // CreateCommand creates a new migration file
@@ -25,6 +24,14 @@ func CreateCommand() *capp.Cmd {
 	return c
 }
 
+// CreateOption represents options for creating migration files
+type CreateOption struct {
+	// Names of the migrations to create
+	Names []string
+	// Path the directory to create the migration files in, default is the first configured migrations path.
+	Path string
+}
+
 // HandleCreate creates migration files
 func HandleCreate(names []string) error {
 	if len(names) == 0 {
@@ -32,12 +39,13 @@ func HandleCreate(names []string) error {
 	}
 
 	// Load configuration
-	if err := initLoadConfig(); err != nil {
+	r, err := newCliRunner()
+	if err != nil {
 		return err
 	}
 
-	migPaths := cfg.Migrations.GetPaths()
-	migPath := migPaths[0]
+	opt := CreateOption{Names: names}
+	migPaths := r.cfg.Migrations.GetPaths()
 	if ln := len(migPaths); ln > 1 {
 		ccolor.Infof("📢 Multiple migration paths found: %v\n", migPaths)
 		for i, p := range migPaths {
@@ -51,19 +59,17 @@ func HandleCreate(names []string) error {
 		// convert to int value
 		if intVal, err1 := strconv.Atoi(str); err1 == nil && intVal > 1 {
 			index := intVal - 1
-			if index < ln {
-				migPath = migPaths[index]
-			} else {
+			if index >= ln {
 				ccolor.Warnf("Invalid index: %d, Exit!\n", intVal)
 				return nil
 			}
+			opt.Path = migPaths[index]
 		}
 	}
 
-	// Create the migration
-	filePaths, err := migration.CreateMigrations(migPath, names)
+	filePaths, err := r.Create(opt)
 	if err != nil {
-		return fmt.Errorf("failed to create migration: %v", err)
+		return err
 	}
 
 	ccolor.Successln("Created migrations:")
@@ -71,5 +77,32 @@ func HandleCreate(names []string) error {
 		ccolor.Printf("  - %s\n", filePath)
 	}
 	return nil
+}
+
+// Create creates migration files by names, returns the created file paths.
+func (r *Runner) Create(opt CreateOption) ([]string, error) {
+	if len(opt.Names) == 0 {
+		return nil, fmt.Errorf("no migration name provided")
+	}
 
+	scheme, err := r.versionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	migPath := opt.Path
+	if migPath == "" {
+		migPath = r.cfg.Migrations.GetPaths()[0]
+	}
+
+	// Create the migration
+	filePaths, err := scheme.CreateMigrations(migPath, opt.Names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration: %v", err)
+	}
+
+	for _, filePath := range filePaths {
+		r.logger.Debug("📝  Created migration file: %s", filePath)
+	}
+	return filePaths, nil
 }
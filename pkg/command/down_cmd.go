@@ -1,11 +1,13 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/gookit/goutil/cflag/capp"
 	"github.com/gookit/goutil/cliutil"
-	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/miglite/internal/database"
 	"github.com/gookit/miglite/pkg/migration"
 )
@@ -15,6 +17,10 @@ type DownOption struct {
 	Number int
 	// Yes 是否跳过确认
 	Yes bool
+	// Timeout for execute each migration, 0 is no timeout.
+	Timeout time.Duration
+	// DryRun only render and show the SQL of rollback migrations, do not execute them.
+	DryRun bool
 }
 
 // DownCommand rolls back the last migration or a specific one
@@ -28,41 +34,89 @@ func DownCommand() *capp.Cmd {
 
 	c.BoolVar(&downOpt.Yes, "yes", false, "Skip confirmation prompt;;y")
 	c.IntVar(&downOpt.Number, "number", 1, "Number of migrations to roll back;;n")
+	c.DurationVar(&downOpt.Timeout, "timeout", 0, "Timeout for execute each migration, eg: 30s, 5m. default no timeout")
+	c.BoolVar(&downOpt.DryRun, "dry-run", false, "Only show the rendered SQL of rollback migrations, do not execute them")
+	bindVarFlag(c)
 	return c
 }
 
-// HandleDown migration logic
+// HandleDown migration logic, and renders the progress to console
 func HandleDown(opt DownOption) error {
-	// Load configuration and connect to database
-	if err := initConfigAndDB(); err != nil {
+	r, err := newCliRunner()
+	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
 
-	// Get applied migrations sorted by date (most recent first)
-	appliedList, err := findAppliedMigrations(db, &opt)
+	ctx, stop := signalContext()
+	defer stop()
+
+	_, err = r.Down(ctx, opt)
+	return err
+}
+
+// Down rolls back the most recent migrations, returns the run report.
+//
+// If the context is canceled, the migration in progress will be rolled back and stop run the remaining.
+func (r *Runner) Down(ctx context.Context, opt DownOption) (*migration.RunReport, error) {
+	// Load configuration and connect to database
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+	db := r.db
+
+	// Initialize or upgrade schema if needed. dry-run does not change the database, the schema is only checked
+	hasSchema, err := r.initSchema(opt.DryRun)
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %v", err)
+		return nil, err
 	}
-	if len(appliedList) == 0 {
-		fmt.Println("🔎  No applied migrations to rollback")
-		return nil
+	if !hasSchema {
+		report := migration.NewRunReport(migration.StatusDown, 0)
+		report.DryRun = opt.DryRun
+		r.renderer.Finish(report.Finish())
+		return report, nil
 	}
 
 	// Discover migrations
-	count := opt.Number
-	migrations, err := findMigrations()
+	migrations, err := r.findMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to discover migrations: %v", err)
+		return nil, fmt.Errorf("failed to discover migrations: %v", err)
 	}
 
+	// Get applied migrations in the reverse order of run (most recent first)
+	appliedList, err := findAppliedMigrations(db, &opt, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %v", err)
+	}
+
+	report := migration.NewRunReport(migration.StatusDown, len(appliedList))
+	if len(appliedList) == 0 {
+		r.renderer.Finish(report.Finish())
+		return report, nil
+	}
+	count := opt.Number
+
 	// Get executor
-	executor := migration.NewExecutor(db, ShowVerbose)
+	executor := r.newExecutor()
+	executor.SetTimeout(opt.Timeout)
 	confirmTip := "Are you sure you want to roll back the migration?"
-	ccolor.Magentaf("🚀  Will roll back recent %d migrations:\n\n", count)
+	report.Total = count
+	report.DryRun = opt.DryRun
+	if !opt.DryRun {
+		if err = r.hooks.BeforeAll(ctx, migration.StatusDown); err != nil {
+			return report.Finish(), fmt.Errorf("before all hook: %w", err)
+		}
+		defer r.hooks.AfterAll(ctx, report)
+	}
+
+	r.renderer.Start(report)
 
 	// Roll back the specified number of migrations
 	for i := 0; i < count; i++ {
+		if err = ctx.Err(); err != nil {
+			return report.Finish(), fmt.Errorf("rollback migrations canceled: %v", err)
+		}
+
 		var applied = appliedList[i]
 		// Find the corresponding migration file
 		var targetMig *migration.Migration
@@ -73,51 +127,67 @@ func HandleDown(opt DownOption) error {
 			}
 		}
 		if targetMig == nil {
-			return fmt.Errorf("migration file not found for version: %s", applied.Version)
+			return report.Finish(), fmt.Errorf("migration file not found for version: %s", applied.Version)
 		}
 
-		ccolor.Printf("%d. Rolling back migration: <ylw>%s</> (appliedAt %s)\n", i+1, targetMig.FileName, formatTime(applied.AppliedAt))
-		if !opt.Yes && !cliutil.Confirm(confirmTip) {
-			ccolor.Warnln("Skipping rollback the migration!")
+		r.renderer.Before(i, targetMig, &applied)
+		res := migration.NewResult(targetMig, migration.StatusDown)
+		if !opt.Yes && !opt.DryRun && !cliutil.Confirm(confirmTip) {
+			report.Add(res.Done(migration.ResultCanceled))
+			r.renderer.After(i, targetMig, res)
 			continue
 		}
 
 		if err = targetMig.Parse(); err != nil {
-			return err
+			report.Add(res.Fail(err))
+			return report.Finish(), err
 		}
 
 		// if down section is empty, skip
-		if targetMig.DownSection == "" {
-			ccolor.Warnln("Skipping empty DOWN migration!")
+		if !targetMig.HasDown() {
+			res.Message = "empty DOWN migration"
+			report.Add(res.Done(migration.ResultIgnored))
+			r.renderer.After(i, targetMig, res)
 			continue
 		}
 
-		if err = executor.ExecuteDown(targetMig); err != nil {
-			return fmt.Errorf(
-				"failed to execute rollback for migration %s: %v.\nDownSQL:\n%s",
-				targetMig.FileName, err, targetMig.DownSection,
-			)
+		if opt.DryRun {
+			res = executor.DryRun(targetMig, migration.StatusDown)
+		} else {
+			res = executor.DownContext(ctx, targetMig)
+		}
+		report.Add(res)
+		r.renderer.After(i, targetMig, res)
+		if res.Err != nil {
+			return report.Finish(), fmt.Errorf("failed to execute rollback for migration %s: %w", targetMig.FileName, res.Err)
 		}
-		ccolor.Printf("✅  Success rolled back migration: %s\n", targetMig.FileName)
 	}
 
-	ccolor.Successf("\n🎉  Successfully rolled back %d migration(s)\n", count)
-	return nil
+	r.renderer.Finish(report.Finish())
+	return report, nil
 }
 
-func findAppliedMigrations(db *database.DB, opt *DownOption) ([]migration.Record, error) {
+func findAppliedMigrations(db *database.DB, opt *DownOption, migrations []*migration.Migration) ([]migration.Record, error) {
 	// Get the target number of migrations to rollback (default 1)
 	count := opt.Number
 	if count <= 0 {
 		return nil, fmt.Errorf("count must be greater than 0")
 	}
 
-	// Get applied migrations sorted by date (most recent first)
-	appliedList, err := migration.GetAppliedSortedByVersion(db, count)
+	records, err := migration.GetRecords(db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get applied migrations: %v", err)
 	}
 
+	// the repeatable migrations are not rolled back
+	var appliedList []migration.Record
+	for _, record := range records {
+		if record.Status == migration.StatusUp && !migration.IsRepeatableFile(record.Version) {
+			appliedList = append(appliedList, record)
+		}
+	}
+	sortForRollback(appliedList, migrations)
+
 	// Limit the number of rollbacks to the available applied migrations
 	if count > len(appliedList) {
 		count = len(appliedList)
@@ -126,3 +196,34 @@ func findAppliedMigrations(db *database.DB, opt *DownOption) ([]migration.Record
 	opt.Number = count
 	return appliedList, nil
 }
+
+// sortForRollback sorts the applied records in the reverse order of run, so a migration is rolled back
+// before the migrations it requires. The record without migration file is placed by its version.
+func sortForRollback(records []migration.Record, migrations []*migration.Migration) {
+	ranks := make(map[string]int, len(migrations))
+	for i, mig := range migrations {
+		ranks[mig.Version] = 2*i + 1
+	}
+
+	rankOf := func(version string) int {
+		if rank, ok := ranks[version]; ok {
+			return rank
+		}
+
+		var before int
+		for _, mig := range migrations {
+			if mig.Version < version {
+				before++
+			}
+		}
+		return 2 * before
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		ri, rj := rankOf(records[i].Version), rankOf(records[j].Version)
+		if ri == rj {
+			return records[i].Version > records[j].Version
+		}
+		return ri > rj
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/gookit/goutil/strutil"
 	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/goutil/x/stdio"
+	"github.com/gookit/miglite/pkg/sqlsplit"
 )
 
 type queryer interface {
@@ -27,6 +28,14 @@ type ExecOption struct {
 	Yes bool
 }
 
+// QueryResult is the result of a query statement, returned by Runner.Exec
+type QueryResult struct {
+	// Index of the statement, start from 1
+	Index   int
+	Columns []string
+	Rows    [][]any
+}
+
 // NewExecCommand executes SQL statement or SQL file directly
 func NewExecCommand() *capp.Cmd {
 	var execOpt = ExecOption{}
@@ -48,19 +57,42 @@ func NewExecCommand() *capp.Cmd {
 }
 
 // HandleExec handles the exec command logic
-func HandleExec(opt ExecOption) (err error) {
+func HandleExec(opt ExecOption) error {
 	// Validate options
-	sqlOrFile := strings.TrimSpace(opt.SQLOrFile)
-	if sqlOrFile == "" {
+	if strings.TrimSpace(opt.SQLOrFile) == "" {
 		return fmt.Errorf("either SQL or sql-file must be provided")
 	}
 
-	// Load configuration and connect to database
-	err = initConfigAndDB()
+	r, err := newCliRunner()
 	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
+
+	results, err := r.Exec(opt)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		printQueryResult(res)
+	}
+	return nil
+}
+
+// Exec executes SQL statements or SQL file directly, all statements are run in one transaction.
+// Returns the results of the query statements.
+func (r *Runner) Exec(opt ExecOption) (results []QueryResult, err error) {
+	// Validate options
+	sqlOrFile := strings.TrimSpace(opt.SQLOrFile)
+	if sqlOrFile == "" {
+		return nil, fmt.Errorf("either SQL or sql-file must be provided")
+	}
+
+	// Load configuration and connect to database
+	if err = r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
 
 	// Prepare SQL to execute
 	var sql = sqlOrFile
@@ -75,33 +107,32 @@ func HandleExec(opt ExecOption) (err error) {
 		// Read SQL from file
 		sql, err = readSQLFromFile(sqlFile)
 		if err != nil {
-			return fmt.Errorf("failed to read SQL file: %v", err)
+			return nil, fmt.Errorf("failed to read SQL file: %v", err)
 		}
 		if sql == "" {
-			return fmt.Errorf("no SQL contents in file: %s", sqlFile)
+			return nil, fmt.Errorf("no SQL contents in file: %s", sqlFile)
 		}
 	}
 
-	ccolor.Infop("📄  Input SQL: ")
-	fmt.Println(sql)
+	r.logger.Info("📄  Input SQL: %s", sql)
 
 	// Confirmation prompt if --yes is not set
 	if !opt.Yes {
-		ccolor.Warnf("⚠️  %s\n", confirmTip)
+		r.logger.Warn("⚠️  %s", confirmTip)
 		if !cliutil.Confirm("Continue?") {
-			ccolor.Magentaln("Exiting SQL execution!")
-			return nil
+			r.logger.Warn("Exiting SQL execution!")
+			return nil, nil
 		}
 	}
 
-	statements := splitSQLStatements(sql)
+	statements := sqlsplit.ForDriver(r.db.Driver()).Strings(sql)
 	if len(statements) == 0 {
-		return fmt.Errorf("no SQL statements to execute")
+		return nil, fmt.Errorf("no SQL statements to execute")
 	}
 
-	tx, err := db.Begin()
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin SQL transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin SQL transaction: %v", err)
 	}
 	committed := false
 	defer func() {
@@ -113,32 +144,36 @@ func HandleExec(opt ExecOption) (err error) {
 	}()
 
 	for i, statement := range statements {
-		ccolor.Printf("🚀  Executing SQL statement %d/%d...\n", i+1, len(statements))
-		if isQuerySQL(statement) {
-			if err = execQuery(tx, statement); err != nil {
-				return fmt.Errorf("failed to execute SQL statement %d: %w", i+1, err)
+		r.logger.Info("🚀  Executing SQL statement %d/%d...", i+1, len(statements))
+		if sqlsplit.IsQuery(statement) {
+			res, queryErr := r.execQuery(tx, statement)
+			if queryErr != nil {
+				return nil, fmt.Errorf("failed to execute SQL statement %d: %w", i+1, queryErr)
 			}
+
+			res.Index = i + 1
+			results = append(results, res)
 			continue
 		}
 
 		result, execErr := tx.Exec(statement)
 		if execErr != nil {
-			return fmt.Errorf("failed to execute SQL statement %d: %w", i+1, execErr)
+			return nil, fmt.Errorf("failed to execute SQL statement %d: %w", i+1, execErr)
 		}
 
 		rowsAffected, resultErr := result.RowsAffected()
 		if resultErr != nil {
-			ccolor.Printf("✅  SQL executed successfully (result info not available)\n")
+			r.logger.Info("✅  SQL executed successfully (result info not available)")
 		} else {
-			ccolor.Printf("✅  SQL executed successfully, rows affected: <green>%d</>\n", rowsAffected)
+			r.logger.Info("✅  SQL executed successfully, rows affected: <green>%d</>", rowsAffected)
 		}
 	}
 
 	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit SQL transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit SQL transaction: %v", err)
 	}
 	committed = true
-	return nil
+	return results, nil
 }
 
 // readSQLFromFile reads SQL content from file
@@ -162,21 +197,21 @@ func readSQLFromFile(filePath string) (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
-func execQuery(db queryer, sql string) error {
+func (r *Runner) execQuery(db queryer, sql string) (res QueryResult, err error) {
 	rows, err := db.Query(sql)
 	if err != nil {
-		return fmt.Errorf("failed to execute query: %v", err)
+		return res, fmt.Errorf("failed to execute query: %v", err)
 	}
 	defer stdio.SafeClose(rows)
 
 	// 获取列名
-	columns, err := rows.Columns()
+	res.Columns, err = rows.Columns()
 	if err != nil {
-		return fmt.Errorf("failed to get columns: %v", err)
+		return res, fmt.Errorf("failed to get columns: %v", err)
 	}
 
 	// 获取行数据
-	var rowsData [][]any
+	columns := res.Columns
 	for rows.Next() {
 		// 创建一个any切片来存储每列的值
 		values := make([]any, len(columns))
@@ -186,7 +221,7 @@ func execQuery(db queryer, sql string) error {
 		}
 
 		if err = rows.Scan(valuePtrs...); err != nil {
-			ccolor.Errorf("Failed to scan row: %v", err)
+			r.logger.Error("Failed to scan row: %v", err)
 			continue
 		}
 
@@ -201,26 +236,29 @@ func execQuery(db queryer, sql string) error {
 				row[i] = val
 			}
 		}
-		rowsData = append(rowsData, row)
+		res.Rows = append(res.Rows, row)
 	}
+	return res, nil
+}
 
+// printQueryResult prints the query result as a table
+func printQueryResult(res QueryResult) {
 	// 输出结果
-	ccolor.Successf("📘  Query Results(size=%d):\n", len(rowsData))
+	ccolor.Successf("📘  Query Results of statement %d(size=%d):\n", res.Index, len(res.Rows))
 	// 输出列名
-	ccolor.Cyanf("  %s\n", strings.Join(columns, "  | "))
+	ccolor.Cyanf("  %s\n", strings.Join(res.Columns, "  | "))
 	sb := strutil.NewBuffer(256)
 	sb.WriteString("----------------------------------------------\n")
 
-	for _, row := range rowsData {
+	for _, row := range res.Rows {
 		sb.WriteString("  ")
 		for i, col := range row {
 			sb.Writef("%v", col)
-			if i < len(columns)-1 {
+			if i < len(res.Columns)-1 {
 				sb.WriteString("  | ")
 			}
 		}
 		sb.WriteRune('\n')
 	}
 	fmt.Println(sb.String())
-	return nil
 }
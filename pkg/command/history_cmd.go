@@ -0,0 +1,131 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/goutil/cflag/capp"
+	"github.com/gookit/goutil/x/ccolor"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// HistoryOption represents options for the history command
+type HistoryOption struct {
+	// Version filter the events by the version keyword
+	Version string
+	// Status filter the events by status or action. eg: failed, up
+	Status string
+	// Since, Until filter the events by time range. format: 2006-01-02 or 2006-01-02 15:04:05
+	Since, Until string
+	// Limit the number of the latest events, 0 is no limit.
+	Limit int
+}
+
+// NewHistoryCommand lists the events in the migration history table
+func NewHistoryCommand() *capp.Cmd {
+	var opt = HistoryOption{}
+	c := capp.NewCmd("history", "List the up, down, skip and failure events of migrations", func(c *capp.Cmd) error {
+		return HandleHistory(opt)
+	})
+
+	c.Aliases = []string{"log"}
+	bindCommonFlags(c)
+	c.StringVar(&opt.Version, "version", "", "Filter the events by the version keyword")
+	c.StringVar(&opt.Status, "status", "", "Filter the events by status or action, eg: applied, rolled, skipped, failed, dirty, up, down;;s")
+	c.StringVar(&opt.Since, "since", "", "Filter the events since the time, eg: 2025-11-05, '2025-11-05 10:24:30'")
+	c.StringVar(&opt.Until, "until", "", "Filter the events until the time, eg: 2025-11-05, '2025-11-05 10:24:30'")
+	c.IntVar(&opt.Limit, "limit", 0, "Only show the number of latest events;;n")
+
+	c.LongHelp = `  <mga>Examples</>:
+  miglite history --status failed
+  miglite history --until '2025-11-05 10:24:30'`
+	return c
+}
+
+// HandleHistory displays the migration history events
+func HandleHistory(opt HistoryOption) error {
+	r, err := newCliRunner()
+	if err != nil {
+		return err
+	}
+
+	events, err := r.History(opt)
+	if err != nil {
+		return err
+	}
+
+	ccolor.Cyanf("\n📜  Migrations History:(total=%d)\n", len(events))
+	fmt.Println(strings.Repeat("==", 56))
+	ccolor.Printf("  <b>%-6s</> | <b>%-19s</> | <b>%-6s</> | <b>%-8s</> | <b>%-48s</> | <b>Operator</>\n", "ID", "Time", "Action", "Status", "Version")
+	fmt.Println(strings.Repeat("--", 56))
+
+	for _, ev := range events {
+		ccolor.Printf("  %-6d | %s | %-6s | %s | %-48s | %s\n", ev.ID, formatTime(ev.CreatedAt), ev.Action,
+			historyStatusText(ev.Status), ev.Version, ev.Operator)
+		if ev.Message != "" {
+			ccolor.Printf("  %6s   <red>%s</>\n", "", ev.Message)
+		}
+	}
+	return nil
+}
+
+func historyStatusText(status string) string {
+	text := fmt.Sprintf("%-8s", status)
+	switch status {
+	case migration.ResultApplied:
+		return "<green>" + text + "</>"
+	case migration.ResultRolled:
+		return "<ylw>" + text + "</>"
+	case migration.ResultFailed, migration.StatusDirty:
+		return "<red>" + text + "</>"
+	default:
+		return "<gray>" + text + "</>"
+	}
+}
+
+// History returns the events in the migration history table, filtered by the options.
+func (r *Runner) History(opt HistoryOption) ([]migration.HistoryEvent, error) {
+	filter := migration.HistoryFilter{Version: opt.Version, Status: opt.Status, Limit: opt.Limit}
+	var err error
+	if filter.Since, err = parseHistoryTime(opt.Since, false); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseHistoryTime(opt.Until, true); err != nil {
+		return nil, err
+	}
+
+	if err = r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	// Initialize or upgrade schema if needed, the history table is created by it.
+	if err = r.db.InitSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize schema: %v", err)
+	}
+	return migration.GetHistory(r.db, filter)
+}
+
+// parseHistoryTime parses the time option in local time zone.
+// If only date is given and isEnd=true, returns the end of the day.
+func parseHistoryTime(str string, isEnd bool) (time.Time, error) {
+	if str = strings.TrimSpace(str); str == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{TimeLayout, "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, str, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %q, allow format: 2006-01-02 or %s", str, TimeLayout)
+	}
+	if isEnd {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
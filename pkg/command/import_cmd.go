@@ -0,0 +1,232 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/goutil/cflag/capp"
+	"github.com/gookit/miglite/pkg/importer"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// ImportOption represents options for the import command
+type ImportOption struct {
+	// From the source migration tool. see importer.Tools
+	From string
+	// Src the migration files directory of the source tool, convert them to miglite format.
+	Src string
+	// History seed the miglite schema table from the history table of the source tool
+	History bool
+	// Table the history table name of the source tool. default see importer.DefaultTable
+	Table string
+	// DryRun only show the import results, do not write files or records.
+	DryRun bool
+}
+
+// NewImportCommand imports the migrations and history from other migration tools
+func NewImportCommand() *capp.Cmd {
+	var opt = ImportOption{}
+	c := capp.NewCmd("import", "Import migration files and history from goose, golang-migrate or flyway", func(c *capp.Cmd) error {
+		return HandleImport(opt)
+	})
+
+	bindCommonFlags(c)
+	c.StringVar(&opt.From, "from", "", "The source migration tool, allow: "+strings.Join(importer.Tools, ", ")+";true;f")
+	c.StringVar(&opt.Src, "src", "", "The migration files directory of the source tool, convert them to the miglite migrations path")
+	c.BoolVar(&opt.History, "history", false, "Seed the migrations table from the history table of the source tool")
+	c.StringVar(&opt.Table, "table", "", "The history table name of the source tool, default: goose_db_version, schema_migrations, flyway_schema_history")
+	c.BoolVar(&opt.DryRun, "dry-run", false, "Only show the import results, do not write files or records")
+
+	c.LongHelp = `  <mga>Examples</>:
+  miglite import --from goose --src ./db/migrations --history
+  miglite import --from flyway --history --table my_flyway_history`
+	return c
+}
+
+// HandleImport imports the migrations and history from other migration tools
+func HandleImport(opt ImportOption) error {
+	r, err := newCliRunner()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	return r.Import(ctx, opt)
+}
+
+// Import converts the migration files and seeds the history records from other migration tool.
+//
+// The imported files have a header line records the source version, so import again will skip them,
+// and the history records are matched by it.
+func (r *Runner) Import(ctx context.Context, opt ImportOption) error {
+	if opt.Src == "" && !opt.History {
+		return fmt.Errorf("nothing to import, please set the files directory or import history")
+	}
+
+	if opt.Src != "" {
+		if err := r.importFiles(opt); err != nil {
+			return err
+		}
+	}
+	if opt.History {
+		return r.importHistory(ctx, opt)
+	}
+	return nil
+}
+
+// importFiles converts the migration files to the first migrations path
+func (r *Runner) importFiles(opt ImportOption) error {
+	migCfg := r.cfg.Migrations
+	if migCfg.FS != nil {
+		return fmt.Errorf("cannot import migration files into the migrations fs.FS")
+	}
+
+	res, err := importer.Convert(opt.From, opt.Src, r.cfg.Database.Driver, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to convert %s migrations: %v", opt.From, err)
+	}
+	for _, file := range res.Skipped {
+		r.logger.Warn("Skipped unsupported %s migration file: %s", opt.From, file)
+	}
+
+	imported, err := r.importedMigrations(opt.From)
+	if err != nil {
+		return err
+	}
+
+	migPath := migCfg.GetPaths()[0]
+	if !opt.DryRun {
+		if err = os.MkdirAll(migPath, 0755); err != nil {
+			return fmt.Errorf("failed to create migrations directory: %v", err)
+		}
+	}
+
+	var count int
+	for _, file := range res.Files {
+		if mig, ok := imported[importer.VersionKey(file.Version)]; ok {
+			r.logger.Info("⏭️  Skipped %s version %s, already imported as %s", opt.From, file.Version, mig.FileName)
+			continue
+		}
+
+		filePath := filepath.Join(migPath, file.FileName)
+		if !opt.DryRun {
+			if err = os.WriteFile(filePath, []byte(file.Contents), 0644); err != nil {
+				return fmt.Errorf("failed to write migration file: %v", err)
+			}
+		}
+		count++
+		r.logger.Info("📄  Imported %s version %s to <green>%s</>", opt.From, file.Version, filePath)
+	}
+
+	r.logger.Info("🎉  Imported %d migration files from %s", count, opt.From)
+	return nil
+}
+
+// importHistory seeds the migration records from the history table of the source tool
+func (r *Runner) importHistory(ctx context.Context, opt ImportOption) error {
+	if err := r.connect(); err != nil {
+		return err
+	}
+	defer r.close()
+	db := r.db
+
+	if err := db.InitSchema(); err != nil {
+		return fmt.Errorf("failed to initialize schema: %v", err)
+	}
+
+	history, err := importer.ReadHistory(ctx, db, opt.From, opt.Table)
+	if err != nil {
+		return err
+	}
+
+	imported, err := r.importedMigrations(opt.From)
+	if err != nil {
+		return err
+	}
+
+	versions := make([]string, 0, len(imported))
+	for version := range imported {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return importer.CompareVersion(versions[i], versions[j]) < 0
+	})
+
+	var count int
+	for _, version := range versions {
+		status := history.Status(version)
+		if status == "" {
+			continue
+		}
+
+		// keep the existing record
+		mig := imported[version]
+		_, current, err := migration.IsApplied(db, mig.Version)
+		if err != nil {
+			return err
+		}
+		if current != "" {
+			r.logger.Info("⏭️  Skipped record of %s, it already has status %s", mig.Version, current)
+			continue
+		}
+
+		if !opt.DryRun {
+			if err = migration.SaveRecordContext(ctx, db, mig.Version, status, nil); err != nil {
+				return err
+			}
+
+			// save the checksum same as the applied migration, so the drift of the file can be checked
+			err = migration.SaveRecordInfoContext(ctx, db, &migration.Record{
+				Version:        mig.Version,
+				Checksum:       mig.Checksum,
+				MigliteVersion: Version,
+				Description:    mig.Description,
+				Kind:           mig.Kind(),
+			}, nil)
+			if err != nil {
+				return err
+			}
+		}
+		count++
+		r.logger.Info("✅  Seeded record of %s, status: %s", mig.Version, migration.StatusText(status))
+	}
+
+	r.logger.Info("🎉  Seeded %d migration records from %s history", count, opt.From)
+	return nil
+}
+
+// importedMigrations finds the migrations imported from the tool, the key is normalized source version.
+func (r *Runner) importedMigrations(tool string) (map[string]*migration.Migration, error) {
+	scheme, err := r.versionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	migCfg := r.cfg.Migrations
+	migrations, err := migration.FindMigrationsWith(migCfg.FS, migCfg.Path, migCfg.Recursive, scheme)
+	// the migrations path may not exist before import files
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to discover migrations: %v", err)
+	}
+
+	imported := make(map[string]*migration.Migration)
+	for _, mig := range migrations {
+		if err = mig.Parse(); err != nil {
+			return nil, err
+		}
+
+		if srcTool, version, ok := importer.ParseSource(mig.Contents); ok && srcTool == tool {
+			imported[importer.VersionKey(version)] = mig
+		}
+		mig.ResetContents()
+	}
+	return imported, nil
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/gookit/goutil/cflag/capp"
-	"github.com/gookit/goutil/x/ccolor"
 )
 
 type InitOption struct {
@@ -27,23 +26,32 @@ func InitCommand() *capp.Cmd {
 
 // HandleInit handles the init command logic
 func HandleInit(opt InitOption) error {
-	if err := initConfigAndDB(); err != nil {
+	r, err := newCliRunner()
+	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
+	return r.Init(opt)
+}
+
+// Init initializes the migration schema on database
+func (r *Runner) Init(opt InitOption) error {
+	if err := r.connect(); err != nil {
+		return err
+	}
+	defer r.close()
 
 	// Drop existing schema if needed
 	if opt.Drop {
-		if err := db.DropSchema(); err != nil {
+		if err := r.db.DropSchema(); err != nil {
 			return fmt.Errorf("failed to drop schema: %v", err)
 		}
 	}
 
 	// Initialize schema if needed
-	if err := db.InitSchema(); err != nil {
+	if err := r.db.InitSchema(); err != nil {
 		return fmt.Errorf("failed to initialize schema: %v", err)
 	}
 
-	ccolor.Infoln("🎉  Migration schema initialized successfully.")
+	r.logger.Info("<info>🎉  Migration schema initialized successfully.</>")
 	return nil
 }
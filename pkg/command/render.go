@@ -0,0 +1,180 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gookit/goutil/x/ccolor"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// Renderer renders the progress of running migrations
+type Renderer interface {
+	// Start is called before processing the migrations
+	Start(report *migration.RunReport)
+	// Before is called before executing a migration.
+	//  - record: the applied record of the migration on rollback, otherwise is nil.
+	Before(idx int, mig *migration.Migration, record *migration.Record)
+	// After is called after a migration is processed, the result status maybe failed or ignored.
+	After(idx int, mig *migration.Migration, res *migration.Result)
+	// Finish is called after all migrations are processed
+	Finish(report *migration.RunReport)
+}
+
+// NopRenderer is a Renderer that renders nothing
+type NopRenderer struct{}
+
+// Start implements Renderer
+func (NopRenderer) Start(*migration.RunReport) {}
+
+// Before implements Renderer
+func (NopRenderer) Before(int, *migration.Migration, *migration.Record) {}
+
+// After implements Renderer
+func (NopRenderer) After(int, *migration.Migration, *migration.Result) {}
+
+// Finish implements Renderer
+func (NopRenderer) Finish(*migration.RunReport) {}
+
+// LogRenderer renders the progress by the migcom.Logger. eg: use with migcom.SlogLogger
+type LogRenderer struct {
+	logger migcom.Logger
+}
+
+// NewLogRenderer creates a new LogRenderer
+func NewLogRenderer(logger migcom.Logger) *LogRenderer {
+	return &LogRenderer{logger: logger}
+}
+
+// Start implements Renderer
+func (r *LogRenderer) Start(report *migration.RunReport) {
+	r.logger.Info("start run %s migrations, total=%d", report.Action, report.Total)
+}
+
+// Before implements Renderer
+func (r *LogRenderer) Before(_ int, mig *migration.Migration, _ *migration.Record) {
+	r.logger.Debug("executing migration: %s", mig.FileName)
+}
+
+// After implements Renderer
+func (r *LogRenderer) After(_ int, mig *migration.Migration, res *migration.Result) {
+	switch res.Status {
+	case migration.ResultFailed:
+		r.logger.Error("migration %s %s failed: %v", mig.Version, res.Action, res.Err)
+	case migration.ResultIgnored:
+		r.logger.Debug("migration %s %s ignored: %s", mig.Version, res.Action, res.Message)
+	case migration.ResultDryRun:
+		r.logger.Info("migration %s %s dry-run SQL:\n%s", mig.Version, res.Action, strings.Join(res.SQL, ";\n"))
+	default:
+		r.logger.Info("migration %s %s %s, duration: %s", mig.Version, res.Action, res.Status, res.Duration)
+	}
+}
+
+// Finish implements Renderer
+func (r *LogRenderer) Finish(report *migration.RunReport) {
+	r.logger.Info("finish run %s migrations, total=%d duration: %s", report.Action, report.Total, report.Duration)
+}
+
+// ConsoleRenderer renders the progress to console with colors. It is used by the CLI commands.
+type ConsoleRenderer struct {
+	// Verbose show details of ignored migrations
+	Verbose bool
+	// split line after ignored dots
+	splitIgnored bool
+}
+
+// NewConsoleRenderer creates a new ConsoleRenderer
+func NewConsoleRenderer(verbose bool) *ConsoleRenderer {
+	return &ConsoleRenderer{Verbose: verbose}
+}
+
+// Start implements Renderer
+func (r *ConsoleRenderer) Start(report *migration.RunReport) {
+	if report.Total == 0 {
+		return
+	}
+
+	switch report.Action {
+	case migration.StatusUp:
+		r.splitIgnored = !r.Verbose
+		ccolor.Printf("🚀  Starting exec migrations(<green>founds=%d</>). Start at: %s\n\n", report.Total, formatTime(report.StartedAt))
+	case migration.StatusDown:
+		ccolor.Magentaf("🚀  Will roll back recent %d migrations:\n\n", report.Total)
+	case migration.StatusSkip:
+		ccolor.Magentaf("🚀  Start ignore %d migrations:\n\n", report.Total)
+	}
+}
+
+// Before implements Renderer
+func (r *ConsoleRenderer) Before(idx int, mig *migration.Migration, record *migration.Record) {
+	if r.splitIgnored {
+		fmt.Println()
+		r.splitIgnored = false
+	}
+
+	if record != nil {
+		ccolor.Printf("%d. Rolling back migration: <ylw>%s</> (appliedAt %s)\n", idx+1, mig.FileName, formatTime(record.AppliedAt))
+	} else {
+		ccolor.Printf("<green>%d.</> 🔄  Executing migration file: <green>%s</>\n", idx+1, mig.FileName)
+	}
+}
+
+// After implements Renderer
+func (r *ConsoleRenderer) After(idx int, mig *migration.Migration, res *migration.Result) {
+	switch res.Status {
+	case migration.ResultApplied:
+		ccolor.Printf("✅  Successfully executed migration: %s\n", mig.FileName)
+	case migration.ResultRolled:
+		ccolor.Printf("✅  Success rolled back migration: %s\n", mig.FileName)
+	case migration.ResultSkipped:
+		ccolor.Printf("- Migration <green>%s</> skipped\n", mig.Version)
+	case migration.ResultCanceled:
+		ccolor.Warnln("Skipping the migration by canceled!")
+	case migration.ResultFailed:
+		ccolor.Errorf("❌  Failed to execute migration: %s\n", mig.FileName)
+	case migration.ResultDryRun:
+		ccolor.Printf("📝  Dry-run %s migration: <green>%s</> %s\n", strings.ToUpper(res.Action), mig.FileName, res.Message)
+		for _, sql := range res.SQL {
+			fmt.Println(sql + ";")
+		}
+		fmt.Println()
+	case migration.ResultIgnored:
+		if res.Action != migration.StatusUp {
+			ccolor.Warnf("Skipping migration %s: %s\n", mig.Version, res.Message)
+		} else if r.Verbose {
+			ccolor.Printf("%d. ⏭️  <ylw>Skipping</> %s migration: %s\n", idx+1, res.Message, mig.FileName)
+		} else {
+			ccolor.Infop(".")
+			r.splitIgnored = true
+		}
+	}
+}
+
+// Finish implements Renderer
+func (r *ConsoleRenderer) Finish(report *migration.RunReport) {
+	if report.DryRun && report.Total > 0 {
+		ccolor.Successf("\n🎉  Dry-run finished, %d migration(s) not executed\n", report.Count(migration.ResultDryRun))
+		return
+	}
+
+	switch report.Action {
+	case migration.StatusUp:
+		if report.Total == 0 {
+			ccolor.Infoln("🔎  No migrations found.")
+			return
+		}
+		ccolor.Successf(
+			"\n\n🎉  All migrations applied successfully! 📘 apply:%d, skip:%d ⏱️ duration: %s\n",
+			report.Count(migration.ResultApplied), report.Count(migration.ResultIgnored), report.Duration,
+		)
+	case migration.StatusDown:
+		if report.Total == 0 {
+			fmt.Println("🔎  No applied migrations to rollback")
+			return
+		}
+		ccolor.Successf("\n🎉  Successfully rolled back %d migration(s)\n", report.Count(migration.ResultRolled))
+	case migration.StatusSkip:
+		ccolor.Successf("\n🎉  Successfully skipped %d migration(s)\n", report.Count(migration.ResultSkipped))
+	}
+}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/gookit/miglite/internal/config"
+	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// Runner runs the migration operations. Each Runner owns its config, database connection,
+// migrations table name and logger, so multiple Runners can be used in one process.
+type Runner struct {
+	cfg *config.Config
+	db  *database.DB
+	// verbose output. default is Config.Verbose
+	verbose bool
+	// table name of the migration records. default is Config.Migrations.Table or database.SchemaTableName
+	tableName string
+	logger    migcom.Logger
+	// renderer for the progress of run migrations. default is NopRenderer
+	renderer Renderer
+	// Go-code migrations added by AddMigrations
+	migrations []*migration.Migration
+	// hooks for the lifecycle of run migrations
+	hooks migration.Hooks
+}
+
+// NewRunner creates a new Runner with the config
+func NewRunner(cfg *config.Config) *Runner {
+	return &Runner{
+		cfg:       cfg,
+		verbose:   cfg.Verbose,
+		tableName: cfg.Migrations.Table,
+		logger:    migcom.Log,
+		renderer:  NopRenderer{},
+	}
+}
+
+// Config returns the config of the runner
+func (r *Runner) Config() *config.Config { return r.cfg }
+
+// DB returns the database connection, is nil before connected.
+func (r *Runner) DB() *database.DB { return r.db }
+
+// SetDB sets the database connection.
+// The connection created by database.NewWithSqlDB will not be closed by the runner.
+func (r *Runner) SetDB(db *database.DB) { r.db = db }
+
+// SetVerbose sets the verbose output
+func (r *Runner) SetVerbose(verbose bool) { r.verbose = verbose }
+
+// SetTableName sets the table name of the migration records
+func (r *Runner) SetTableName(tableName string) { r.tableName = tableName }
+
+// SetLogger sets the logger
+func (r *Runner) SetLogger(logger migcom.Logger) { r.logger = logger }
+
+// SetRenderer sets the renderer for the progress of run migrations
+func (r *Runner) SetRenderer(renderer Renderer) { r.renderer = renderer }
+
+// AddMigrations adds Go-code migrations, they will be merged with the migration files.
+func (r *Runner) AddMigrations(migs ...*migration.Migration) {
+	r.migrations = append(r.migrations, migs...)
+}
+
+// AddHook adds hooks for the lifecycle of run migrations
+func (r *Runner) AddHook(hooks ...migration.Hook) {
+	r.hooks = append(r.hooks, hooks...)
+}
+
+// connect to the database if not connected
+func (r *Runner) connect() (err error) {
+	if r.tableName != "" {
+		if err = database.CheckTableName(r.tableName); err != nil {
+			return err
+		}
+	}
+
+	if r.db == nil {
+		dbCfg := r.cfg.Database
+		r.db, err = database.NewDB(dbCfg.Driver, dbCfg.SqlDriver, dbCfg.DSN)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %v", err)
+		}
+		r.logger.Info("✅  Database connect successful! driver: <green>%s</>", r.db.Driver())
+	}
+
+	r.db.SetDebug(r.verbose)
+	r.db.SetLogger(r.logger)
+	r.db.SetTableName(r.tableName)
+	return nil
+}
+
+// close the database connection opened by the runner. external connection is kept open.
+func (r *Runner) close() {
+	if r.db != nil && !r.db.IsExternal() {
+		r.db.SilentClose()
+		r.db = nil
+	}
+}
+
+func (r *Runner) newExecutor() *migration.Executor {
+	executor := migration.NewExecutor(r.db, r.verbose)
+	executor.SetLogger(r.logger)
+	executor.AddHook(r.hooks...)
+	executor.SetVars(r.cfg.Vars)
+	executor.SetVersion(Version)
+	return executor
+}
+
+// findMigrations finds migration files and merges them with the Go-code migrations
+func (r *Runner) findMigrations() ([]*migration.Migration, error) {
+	scheme, err := r.versionScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	migCfg := r.cfg.Migrations
+	r.logger.Info("🔎  Discovering migrations from <green>%s</>", migCfg.Path)
+	migrations, err := migration.FindMigrationsWith(migCfg.FS, migCfg.Path, migCfg.Recursive, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	// select the driver-specific sections on parse
+	if r.db != nil {
+		for _, mig := range migrations {
+			mig.Driver = r.db.Driver()
+		}
+	}
+
+	// merge the Go-code migrations
+	return migration.Merge(migrations, migration.Registered(), r.migrations)
+}
+
+// versionScheme returns the version scheme of migration files by config
+func (r *Runner) versionScheme() (*migration.VersionScheme, error) {
+	scheme, err := migration.ParseVersionScheme(r.cfg.Migrations.VersionScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme.Paths = r.cfg.Migrations.GetPaths()
+	return scheme, nil
+}
+
+// findGoMigration find Go-code migration by version
+func (r *Runner) findGoMigration(version string) *migration.Migration {
+	for _, list := range [][]*migration.Migration{r.migrations, migration.Registered()} {
+		for _, mig := range list {
+			if mig.Version == version {
+				return mig
+			}
+		}
+	}
+	return nil
+}
@@ -36,69 +36,118 @@ func NewShowCommand() *capp.Cmd {
 	return c
 }
 
+// ShowResult is the database information returned by Runner.Show
+type ShowResult struct {
+	// Tables in the database, the migrations tables are excluded. for ShowOption.Tables
+	Tables []string
+	// Columns of the table. for ShowOption.Schema
+	Columns []database.ColumnInfo
+}
+
 // HandleShow handles the show command logic
 func HandleShow(opt ShowOption) error {
-	// Validate options
-	if !opt.Tables && opt.Schema == "" {
-		return fmt.Errorf("either --tables or --schema must be provided")
+	if err := opt.validate(); err != nil {
+		return err
 	}
-	if opt.Tables && opt.Schema != "" {
-		return fmt.Errorf("--tables and --schema cannot be used together")
+
+	r, err := newCliRunner()
+	if err != nil {
+		return err
+	}
+
+	if opt.Tables {
+		ccolor.Println("🔍  Fetching database tables...")
+	} else {
+		ccolor.Printf("🔍  Fetching schema for table: <green>%s</>\n", opt.Schema)
 	}
 
-	// Load configuration and connect to database
-	if err := initConfigAndDB(); err != nil {
+	res, err := r.Show(opt)
+	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
 
-	// Show database tables
 	if opt.Tables {
-		return showTables(db)
+		printTables(res.Tables)
+	} else {
+		printTableSchema(opt.Schema, res.Columns)
 	}
+	return nil
+}
 
-	// Show table schema
-	if opt.Schema != "" {
-		return showTableSchema(db, opt.Schema)
+func (opt ShowOption) validate() error {
+	if !opt.Tables && opt.Schema == "" {
+		return fmt.Errorf("either --tables or --schema must be provided")
+	}
+	if opt.Tables && opt.Schema != "" {
+		return fmt.Errorf("--tables and --schema cannot be used together")
 	}
 	return nil
 }
 
-// showTables displays all tables in the database
-func showTables(db *database.DB) error {
-	ccolor.Println("🔍  Fetching database tables...")
+// Show returns database information like tables or table schema
+func (r *Runner) Show(opt ShowOption) (*ShowResult, error) {
+	// Validate options
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	// Connect to database
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	res := &ShowResult{}
+	var err error
+	if opt.Tables {
+		// Show database tables
+		res.Tables, err = showTables(r.db)
+	} else {
+		// Show table schema
+		res.Columns, err = r.db.QueryTableSchema(opt.Schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
 
+// showTables returns all tables in the database, the migrations tables are excluded.
+func showTables(db *database.DB) ([]string, error) {
 	tables, err := db.ShowTables()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// exclude the migrations table and its meta, history tables. table name maybe schema-qualified.
+	// compare without case: postgres folds the unquoted table name to lower case.
+	skips := []string{db.TableName(), db.MetaTableName(), db.HistoryTableName()}
+	for i, name := range skips {
+		skips[i] = strings.ToLower(name[strings.LastIndexByte(name, '.')+1:])
 	}
+	return arrutil.Filter(tables, func(s string) bool {
+		return !arrutil.InStrings(strings.ToLower(s), skips)
+	}), nil
+}
+
+// printTables displays all tables in the database
+func printTables(tables []string) {
 	if len(tables) == 0 {
 		ccolor.Infoln("No tables found in the database.")
-		return nil
+		return
 	}
 
-	tables = arrutil.Filter(tables, func(s string) bool {
-		return s != database.SchemaTableName
-	})
-
 	ccolor.Printf("📋  Found <green>%d</> table(s):\n", len(tables))
 	for i, table := range tables {
 		ccolor.Printf("  %d. %s\n", i+1, table)
 	}
-	return nil
 }
 
-// showTableSchema displays the schema of a specific table
-func showTableSchema(db *database.DB, tableName string) error {
-	ccolor.Printf("🔍  Fetching schema for table: <green>%s</>\n", tableName)
-	columns, err := db.QueryTableSchema(tableName)
-	if err != nil {
-		return err
-	}
-
+// printTableSchema displays the schema of a specific table
+func printTableSchema(tableName string, columns []database.ColumnInfo) {
 	if len(columns) == 0 {
 		ccolor.Warnf("No columns found for table: %s\n", tableName)
-		return nil
+		return
 	}
 
 	hLine := strings.Repeat("-", 110)
@@ -113,6 +162,4 @@ func showTableSchema(db *database.DB, tableName string) error {
 		)
 	}
 	fmt.Println(hLine)
-
-	return nil
 }
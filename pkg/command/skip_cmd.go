@@ -1,9 +1,11 @@
 package command
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/gookit/goutil/arrutil"
 	"github.com/gookit/goutil/cflag/capp"
-	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/miglite/pkg/migration"
 )
 
@@ -27,43 +29,101 @@ func SkipCommand() *capp.Cmd {
 	return c
 }
 
-// HandleSkip skips one or multi migration file(s)
+// HandleSkip skips one or multi migration file(s), and renders the progress to console
 func HandleSkip(opt SkipOption) error {
-	if err := initConfigAndDB(); err != nil {
+	r, err := newCliRunner()
+	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
 
-	migFiles, err := migration.MigrationsFrom(cfg.Migrations.Path, opt.FileNames)
+	_, err = r.Skip(context.Background(), opt)
+	return err
+}
+
+// Skip skips one or multi migration file(s), returns the run report.
+func (r *Runner) Skip(ctx context.Context, opt SkipOption) (*migration.RunReport, error) {
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+	db := r.db
+
+	migFiles, err := r.skipMigrationsFrom(opt.FileNames)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Initialize or upgrade schema if needed
+	if err = db.InitSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize schema: %v", err)
 	}
 
 	// get migration status from database
 	records, err := migration.GetMigrationsStatus(db, migFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	recordMap := arrutil.ToMap(records, func(item migration.Record) (string, migration.Record) {
 		return item.Version, item
 	})
 
-	ccolor.Magentaf("🚀  Start ignore %d migrations:\n\n", len(migFiles))
-	for _, migFile := range migFiles {
+	operator := migration.CurrentOperator()
+	report := migration.NewRunReport(migration.StatusSkip, len(migFiles))
+	r.renderer.Start(report)
+	for idx, migFile := range migFiles {
+		res := migration.NewResult(migFile, migration.StatusSkip)
 		if record, ok := recordMap[migFile.Version]; ok {
 			if record.Status == migration.StatusUp {
-				ccolor.Warnf("Migration %s already skipped", migFile.Version)
+				res.Message = "already applied"
+				report.Add(res.Done(migration.ResultIgnored))
+				r.renderer.After(idx, migFile, res)
 				continue
 			}
 		}
 
 		// update migration status to skipped
-		err = migration.SaveRecord(db, migFile.Version, migration.StatusSkip, nil)
+		err = migration.SaveRecordContext(ctx, db, migFile.Version, migration.StatusSkip, nil)
 		if err != nil {
-			return err
+			report.Add(res.Fail(err))
+			return report.Finish(), err
+		}
+		report.Add(res.Done(migration.ResultSkipped))
+
+		ev := &migration.HistoryEvent{Version: migFile.Version, Action: migration.StatusSkip, Status: migration.ResultSkipped, Operator: operator}
+		if err = migration.SaveHistoryContext(ctx, db, ev, nil); err != nil {
+			return report.Finish(), err
 		}
-		ccolor.Printf("- Migration <green>%s</> skipped", migFile.Version)
+		r.renderer.After(idx, migFile, res)
 	}
 
-	return nil
+	r.renderer.Finish(report.Finish())
+	return report, nil
+}
+
+// skipMigrationsFrom resolves migrations from Go-code versions or file names
+func (r *Runner) skipMigrationsFrom(names []string) ([]*migration.Migration, error) {
+	var fileNames []string
+	var migrations []*migration.Migration
+	for _, name := range names {
+		if mig := r.findGoMigration(name); mig != nil {
+			migrations = append(migrations, mig)
+		} else {
+			fileNames = append(fileNames, name)
+		}
+	}
+
+	if len(fileNames) > 0 {
+		scheme, err := r.versionScheme()
+		if err != nil {
+			return nil, err
+		}
+
+		migCfg := r.cfg.Migrations
+		migFiles, err := migration.MigrationsFromWith(migCfg.FS, migCfg.Path, fileNames, scheme)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migFiles...)
+	}
+	return migrations, nil
 }
@@ -1,13 +1,11 @@
 package command
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/gookit/goutil/cflag/capp"
 	"github.com/gookit/goutil/x/ccolor"
-	"github.com/gookit/miglite/internal/migutil"
 	"github.com/gookit/miglite/pkg/migration"
 )
 
@@ -30,46 +28,101 @@ func StatusCommand() *capp.Cmd {
 	return c
 }
 
+// StatusReport is the status of all migrations, returned by Runner.Status
+type StatusReport struct {
+	// Statuses of all migrations in run order, the not applied migration has status StatusPending
+	Statuses []migration.Record
+	// Drifts the applied migrations whose file has been changed or deleted
+	Drifts []migration.Drift
+	// OutOfOrder the pending migrations older than the newest applied migration
+	OutOfOrder []*migration.Migration
+}
+
 // HandleStatus display migration status
-func HandleStatus(_ StatusOption) error {
-	// Load configuration and connect to database
-	if err := initConfigAndDB(); err != nil {
+func HandleStatus(opt StatusOption) error {
+	r, err := newCliRunner()
+	if err != nil {
 		return err
 	}
-	defer db.SilentClose()
 
-	// Discover migrations
-	migrations, err := findMigrations()
+	report, err := r.Status(opt)
 	if err != nil {
-		return fmt.Errorf("failed to discover migrations: %v", err)
+		return err
 	}
 
-	// Get migration statuses
-	statuses, err := migration.GetMigrationsStatus(db, migrations)
-	if err != nil {
-		if migutil.IsTableNotExists(db.Driver(), err.Error()) {
-			err = errors.New("migration table does not exist. please run `miglite init` to create it")
-		}
-		return err
+	outOfOrderMap := make(map[string]bool, len(report.OutOfOrder))
+	for _, mig := range report.OutOfOrder {
+		outOfOrderMap[mig.Version] = true
 	}
 
 	// Print status table
-	ccolor.Cyanf("\n📊  Migrations Status:(total=%d)\n", len(statuses))
+	ccolor.Cyanf("\n📊  Migrations Status:(total=%d)\n", len(report.Statuses))
 	fmt.Println(strings.Repeat("==", 44))
 	ccolor.Printf("  <b>Status</>  | %13s<b>Version(migration file)</>%13s    |   <b>Operate Time</> \n", "", "")
 	fmt.Println(strings.Repeat("--", 44))
 
-	for _, st := range statuses {
+	for _, st := range report.Statuses {
+		version := st.Version
 		statusIcon := "<mga>pending</>" // ⏳  pending
-		if st.Status == "up" {
+		if outOfOrderMap[st.Version] {
+			statusIcon = "<red>pending</>" // ⚠️ older than the newest applied
+			version += " (out-of-order)"
+		} else if st.Status == "up" {
 			statusIcon = "<green>applied</>" // ✅ applied
 		} else if st.Status == "down" {
 			statusIcon = "<ylw>rolled</> " // ↪️ rolled back
 		} else if st.Status == "skip" {
 			statusIcon = "<gray>skipped</>" // ⏭️ skipped
+		} else if st.Status == migration.StatusDirty {
+			statusIcon = "<red>dirty</>  " // ⚠️ half-applied
+		} else if st.Status == migration.StatusOutdated {
+			statusIcon = "<cyan>outdated</>" // 🔁 repeatable file changed
 		}
-		ccolor.Printf("  %s | %-52s | %s\n", statusIcon, st.Version, formatTime(st.AppliedAt))
+		ccolor.Printf("  %s | %-52s | %s\n", statusIcon, version, formatTime(st.AppliedAt))
 	}
 
+	if len(report.Drifts) > 0 {
+		fmt.Println()
+		r.warnDrifts(report.Drifts)
+	}
+	if len(report.OutOfOrder) > 0 {
+		fmt.Println()
+		r.warnOutOfOrder(report.OutOfOrder)
+	}
 	return nil
 }
+
+// Status returns the status of all migrations, and the drifts, out-of-order migrations.
+func (r *Runner) Status(_ StatusOption) (*StatusReport, error) {
+	// Connect to database
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	// Discover migrations
+	migrations, err := r.findMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover migrations: %v", err)
+	}
+
+	// Get migration statuses
+	records, err := r.queryRecords()
+	if err != nil {
+		return nil, err
+	}
+	drifts, err := r.findDrifts(records, migrations)
+	if err != nil {
+		return nil, err
+	}
+	outOfOrder, err := r.findOutOfOrder(records, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusReport{
+		Statuses:   migration.StatusOf(records, migrations),
+		Drifts:     drifts,
+		OutOfOrder: outOfOrder,
+	}, nil
+}
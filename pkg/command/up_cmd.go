@@ -1,12 +1,13 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/gookit/goutil/cflag/capp"
 	"github.com/gookit/goutil/cliutil"
-	"github.com/gookit/goutil/x/ccolor"
+	"github.com/gookit/miglite/internal/database"
 	"github.com/gookit/miglite/pkg/migration"
 )
 
@@ -20,6 +21,12 @@ type UpOption struct {
 	Number int
 	// 查找迁移开始时间，默认只查找最近6个月的迁移文件
 	StartTime string
+	// Timeout for execute each migration, 0 is no timeout.
+	Timeout time.Duration
+	// DryRun only render and show the SQL of pending migrations, do not execute them.
+	DryRun bool
+	// AllowOutOfOrder run the out-of-order migrations even if the policy is error. see Config.Migrations.OutOfOrder
+	AllowOutOfOrder bool
 }
 
 // NewUpCommand executes pending migrations
@@ -36,84 +43,153 @@ func NewUpCommand() *capp.Cmd {
 	c.BoolVar(&upOpt.Yes, "yes", false, "Skip confirmation prompt;;y")
 	c.IntVar(&upOpt.Number, "number", 0, "Execute only the specified number of migrations;;n")
 	c.BoolVar(&upOpt.SkipErr, "skip-err", false, "Skip the error migration and continue with the execution;;s")
+	c.DurationVar(&upOpt.Timeout, "timeout", 0, "Timeout for execute each migration, eg: 30s, 5m. default no timeout")
+	c.BoolVar(&upOpt.DryRun, "dry-run", false, "Only show the rendered SQL of pending migrations, do not execute them")
+	c.BoolVar(&upOpt.AllowOutOfOrder, "allow-out-of-order", false, "Run the pending migrations older than the newest applied migration")
+	bindVarFlag(c)
 
 	// c.LongHelp = `  <mga>Note</>: if set --number, will auto set --yes=true`
 	return c
 }
 
-// HandleUp executes pending migrations
+// HandleUp executes pending migrations, and renders the progress to console
 func HandleUp(opt UpOption) error {
+	r, err := newCliRunner()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	_, err = r.Up(ctx, opt)
+	return err
+}
+
+// Up executes pending migrations, returns the run report.
+//
+// If the context is canceled, the migration in progress will be rolled back and stop run the remaining.
+func (r *Runner) Up(ctx context.Context, opt UpOption) (*migration.RunReport, error) {
 	// Load configuration and connect to database
-	if err1 := initConfigAndDB(); err1 != nil {
-		return fmt.Errorf("failed to connect to database: %v", err1)
+	if err1 := r.connect(); err1 != nil {
+		return nil, err1
 	}
-	defer db.SilentClose()
+	defer r.close()
+	db := r.db
 
-	// Initialize schema if needed
-	if err := db.InitSchema(); err != nil {
-		return fmt.Errorf("failed to initialize schema: %v", err)
+	// Initialize schema if needed. dry-run does not change the database, the schema is only checked
+	hasSchema, err := r.initSchema(opt.DryRun)
+	if err != nil {
+		return nil, err
 	}
 
 	// Discover migrations
-	migrations, err2 := findMigrations()
+	migrations, err2 := r.findMigrations()
 	if err2 != nil {
-		return fmt.Errorf("failed to discover migrations: %v", err2)
+		return nil, fmt.Errorf("failed to discover migrations: %v", err2)
 	}
 
-	if len(migrations) == 0 {
-		ccolor.Infoln("🔎  No migrations found.")
-		return nil
+	// Warn the applied migrations whose file has been changed or deleted
+	var records []migration.Record
+	if hasSchema {
+		if records, err = migration.GetRecords(db); err != nil {
+			return nil, err
+		}
+	}
+	drifts, err := r.findDrifts(records, migrations)
+	if err != nil {
+		return nil, err
+	}
+	if len(drifts) > 0 {
+		r.warnDrifts(drifts)
+	}
+
+	// Check the pending migrations older than the newest applied migration
+	if err = r.checkOutOfOrder(records, migrations, opt.AllowOutOfOrder); err != nil {
+		return nil, err
 	}
 
 	// Get executor
-	executor := migration.NewExecutor(db, ShowVerbose)
-	startTime := time.Now()
+	executor := r.newExecutor()
+	executor.SetTimeout(opt.Timeout)
+	report := migration.NewRunReport(migration.StatusUp, len(migrations))
+	report.DryRun = opt.DryRun
+	if !opt.DryRun {
+		if err := r.hooks.BeforeAll(ctx, migration.StatusUp); err != nil {
+			return report.Finish(), fmt.Errorf("before all hook: %w", err)
+		}
+		defer r.hooks.AfterAll(ctx, report)
+	}
 
-	var appliedNum, skippedNum int
-	var splitSkipped = !ShowVerbose
 	confirmTip := "Are you sure you want to execute this migration?"
-	ccolor.Printf("🚀  Starting exec migrations(<green>founds=%d</>). Start at: %s\n\n", len(migrations), formatTime(startTime))
+	r.renderer.Start(report)
 
 	// Execute pending migrations
+	var appliedNum int
 	for idx, mig := range migrations {
-		// Check if migration is already applied
-		applied, status, err := migration.IsApplied(db, mig.FileName)
-		if err != nil {
-			return err
+		if err := ctx.Err(); err != nil {
+			return report.Finish(), fmt.Errorf("run migrations canceled: %v", err)
 		}
-		if applied || status == migration.StatusSkip {
-			skippedNum++
-			if ShowVerbose {
-				ccolor.Printf("%d. ⏭️  <ylw>Skipping</> %s migration: %s\n", idx+1, migration.StatusText(status), mig.FileName)
-			} else {
-				ccolor.Infop(".")
-				splitSkipped = true
+
+		// Check if migration is already applied. nothing is applied if the migrations table not exists on dry-run
+		var applied bool
+		var status string
+		if hasSchema {
+			if applied, status, err = migration.IsApplied(db, mig.Version); err != nil {
+				return report.Finish(), err
 			}
-			continue
 		}
-
-		if splitSkipped {
-			fmt.Println()
-			splitSkipped = false
+		if status == migration.StatusDirty {
+			err = fmt.Errorf("migration %s is half-applied(dirty), please fix the database manually, then run `miglite skip %s` to mark it as done", mig.Version, mig.Version)
+			report.Add(migration.NewResult(mig, migration.StatusUp).Fail(err))
+			return report.Finish(), err
+		}
+		// the repeatable migration runs again when the file has been changed
+		if applied && mig.Repeatable {
+			if applied, err = isRepeatableApplied(db, mig); err != nil {
+				report.Add(migration.NewResult(mig, migration.StatusUp).Fail(err))
+				return report.Finish(), err
+			}
+		}
+		if applied || status == migration.StatusSkip {
+			res := migration.NewResult(mig, migration.StatusUp)
+			res.Message = migration.StatusText(status)
+			report.Add(res.Done(migration.ResultIgnored))
+			r.renderer.After(idx, mig, res)
+			continue
 		}
 
 		// not applied OR status=down
-		ccolor.Printf("<green>%d.</> 🔄  Executing migration file: <green>%s</>\n", idx+1, mig.FileName)
-		if !opt.Yes && !cliutil.Confirm(confirmTip) {
-			ccolor.Warnln("Exiting run migrations!")
+		r.renderer.Before(idx, mig, nil)
+		if !opt.Yes && !opt.DryRun && !cliutil.Confirm(confirmTip) {
+			r.logger.Warn("Exiting run migrations!")
+			report.Add(migration.NewResult(mig, migration.StatusUp).Done(migration.ResultCanceled))
 			break
 		}
 
 		if err = mig.Parse(); err != nil {
-			return err
+			report.Add(migration.NewResult(mig, migration.StatusUp).Fail(err))
+			return report.Finish(), err
+		}
+
+		var res *migration.Result
+		if opt.DryRun {
+			res = executor.DryRun(mig, migration.StatusUp)
+		} else {
+			res = executor.UpContext(ctx, mig)
 		}
-		if err = executor.ExecuteUp(mig); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %v\nUpSQL:\n%s", mig.FileName, err, mig.UpSection)
+		report.Add(res)
+		r.renderer.After(idx, mig, res)
+		if err = res.Err; err != nil {
+			return report.Finish(), fmt.Errorf("failed to execute migration %s: %w", mig.FileName, err)
 		}
 
 		// free memory
 		mig.ResetContents()
-		ccolor.Printf("✅  Successfully executed migration: %s\n", mig.FileName)
+		// eg: not for the current driver
+		if res.Status == migration.ResultIgnored {
+			continue
+		}
 
 		appliedNum++
 		if opt.Number > 0 && appliedNum >= opt.Number {
@@ -121,6 +197,70 @@ func HandleUp(opt UpOption) error {
 		}
 	}
 
-	ccolor.Successf("\n\n🎉  All migrations applied successfully! 📘 apply:%d, skip:%d ⏱️ duration: %s\n", appliedNum, skippedNum, time.Since(startTime))
+	r.renderer.Finish(report.Finish())
+	return report, nil
+}
+
+// initSchema creates or upgrades the migrations table. returns whether the migrations table exists.
+//   - dryRun: do not change the database, only check the table exists and is upgraded.
+func (r *Runner) initSchema(dryRun bool) (bool, error) {
+	if dryRun {
+		return r.db.CheckSchema()
+	}
+
+	if err := r.db.InitSchema(); err != nil {
+		return false, fmt.Errorf("failed to initialize schema: %v", err)
+	}
+	return true, nil
+}
+
+// checkOutOfOrder checks the out-of-order pending migrations by the policy of config.
+//   - allow: the --allow-out-of-order flag, only warn them when the policy is error.
+func (r *Runner) checkOutOfOrder(records []migration.Record, migrations []*migration.Migration, allow bool) error {
+	policy, err := migration.ParseOutOfOrderPolicy(r.cfg.Migrations.OutOfOrder)
+	if err != nil {
+		return err
+	}
+
+	migs, err := r.findOutOfOrder(records, migrations)
+	if err != nil || len(migs) == 0 || policy == migration.OutOfOrderAllow {
+		return err
+	}
+
+	r.warnOutOfOrder(migs)
+	if policy == migration.OutOfOrderError && !allow {
+		return fmt.Errorf("found %d out-of-order pending migrations, run with --allow-out-of-order to apply them", len(migs))
+	}
 	return nil
 }
+
+// findOutOfOrder finds the out-of-order pending migrations, the migrations not for the current driver are excluded.
+func (r *Runner) findOutOfOrder(records []migration.Record, migrations []*migration.Migration) ([]*migration.Migration, error) {
+	// parse the options of found migrations, then find again to exclude by the drivers option
+	for _, mig := range migration.FindOutOfOrder(records, migrations, r.db.Driver()) {
+		if err := mig.Parse(); err != nil {
+			return nil, err
+		}
+	}
+	return migration.FindOutOfOrder(records, migrations, r.db.Driver()), nil
+}
+
+func (r *Runner) warnOutOfOrder(migs []*migration.Migration) {
+	r.logger.Warn("⚠️  Found %d pending migrations older than the newest applied migration(out-of-order):", len(migs))
+	for _, mig := range migs {
+		r.logger.Warn("  - %s", mig.Version)
+	}
+}
+
+// isRepeatableApplied checks the repeatable migration is applied and the file has not been changed
+func isRepeatableApplied(db *database.DB, mig *migration.Migration) (bool, error) {
+	if err := mig.Parse(); err != nil {
+		return false, err
+	}
+
+	record, err := migration.GetRecord(db, mig.Version)
+	if err != nil {
+		return false, err
+	}
+	return record != nil && record.Checksum == mig.Checksum, nil
+}
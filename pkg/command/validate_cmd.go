@@ -0,0 +1,112 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gookit/goutil/cflag/capp"
+	"github.com/gookit/miglite/internal/migutil"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// ValidateOption validate command option
+type ValidateOption struct {
+}
+
+// NewValidateCommand checks the applied migrations whose file has been changed or deleted
+func NewValidateCommand() *capp.Cmd {
+	opt := ValidateOption{}
+
+	c := capp.NewCmd("validate", "Check the applied migrations whose file has been changed or deleted", func(c *capp.Cmd) error {
+		return HandleValidate(opt)
+	})
+	bindCommonFlags(c)
+
+	return c
+}
+
+// HandleValidate validates the applied migrations
+func HandleValidate(opt ValidateOption) error {
+	r, err := newCliRunner()
+	if err != nil {
+		return err
+	}
+	_, err = r.Validate(opt)
+	return err
+}
+
+// Validate checks the applied migrations by the saved checksum, returns the changed or deleted migrations.
+// If found any drift, will return an error.
+func (r *Runner) Validate(_ ValidateOption) ([]migration.Drift, error) {
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	migrations, err := r.findMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover migrations: %v", err)
+	}
+
+	records, err := r.queryRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	drifts, err := r.findDrifts(records, migrations)
+	if err != nil {
+		return nil, err
+	}
+	if len(drifts) > 0 {
+		r.warnDrifts(drifts)
+		return drifts, fmt.Errorf("found %d applied migrations changed or deleted", len(drifts))
+	}
+
+	r.logger.Info("<info>✅  All applied migrations are valid.</>")
+	return nil, nil
+}
+
+// queryRecords queries all migration records. add the new columns for the table created by old version
+func (r *Runner) queryRecords() ([]migration.Record, error) {
+	records, err := migration.GetRecords(r.db)
+	if err != nil && !migutil.IsTableNotExists(r.db.Driver(), err.Error()) {
+		if err = r.db.UpgradeSchema(); err == nil {
+			records, err = migration.GetRecords(r.db)
+		}
+	}
+
+	if err != nil && migutil.IsTableNotExists(r.db.Driver(), err.Error()) {
+		return nil, errors.New("migration table does not exist. please run `miglite init` to create it")
+	}
+	return records, err
+}
+
+// findDrifts parses the applied migrations for the checksum, then finds the changed or deleted migrations.
+func (r *Runner) findDrifts(records []migration.Record, migrations []*migration.Migration) ([]migration.Drift, error) {
+	applied := make(map[string]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = record.Status == migration.StatusUp
+	}
+
+	for _, mig := range migrations {
+		if applied[mig.Version] && !mig.IsGoCode() && mig.Checksum == "" {
+			if err := mig.Parse(); err != nil {
+				return nil, err
+			}
+			mig.ResetContents()
+		}
+	}
+	return migration.FindDrifts(records, migrations), nil
+}
+
+// warnDrifts logs the changed or deleted migrations
+func (r *Runner) warnDrifts(drifts []migration.Drift) {
+	r.logger.Warn("⚠️  Found %d applied migrations changed or deleted:", len(drifts))
+	for _, d := range drifts {
+		if d.Reason == migration.DriftMissing {
+			r.logger.Warn("  - %s: file is deleted", d.Version)
+		} else {
+			r.logger.Warn("  - %s: file is modified, checksum %.12s => %.12s", d.Version, d.Checksum, d.Current)
+		}
+	}
+}
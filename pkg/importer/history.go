@@ -0,0 +1,193 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// DefaultTable returns the default history table name of the tool
+func DefaultTable(tool string) string {
+	switch tool {
+	case Goose:
+		return "goose_db_version"
+	case GolangMigrate:
+		return "schema_migrations"
+	case Flyway:
+		return "flyway_schema_history"
+	}
+	return ""
+}
+
+// History is the applied migrations in the history table of the tool
+type History struct {
+	// Applied versions
+	Applied []string
+	// Failed versions. eg: flyway success=false
+	Failed []string
+	// Current version, all versions before it are applied. eg: golang-migrate version, flyway baseline
+	Current string
+	// Dirty the Current version is dirty, it is half-applied.
+	Dirty bool
+}
+
+// Status returns the miglite status of the source version: StatusUp, StatusDirty. empty if not applied.
+func (h *History) Status(version string) string {
+	for _, v := range h.Failed {
+		if CompareVersion(v, version) == 0 {
+			return migration.StatusDirty
+		}
+	}
+	for _, v := range h.Applied {
+		if CompareVersion(v, version) == 0 {
+			return migration.StatusUp
+		}
+	}
+
+	if h.Current != "" {
+		switch cmp := CompareVersion(version, h.Current); {
+		case cmp < 0:
+			return migration.StatusUp
+		case cmp == 0 && h.Dirty:
+			return migration.StatusDirty
+		case cmp == 0:
+			return migration.StatusUp
+		}
+	}
+	return ""
+}
+
+// querier for query the history table. eg: *sql.DB
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// ReadHistory reads the applied migrations from the history table of the tool.
+//
+//   - table: the history table name, empty will use DefaultTable
+func ReadHistory(ctx context.Context, db querier, tool, table string) (*History, error) {
+	if table == "" {
+		table = DefaultTable(tool)
+	}
+
+	h := &History{}
+	var err error
+	switch tool {
+	case Goose:
+		err = readGooseHistory(ctx, db, table, h)
+	case GolangMigrate:
+		err = readMigrateHistory(ctx, db, table, h)
+	case Flyway:
+		err = readFlywayHistory(ctx, db, table, h)
+	default:
+		return nil, fmt.Errorf("unsupported migration tool %q, allow: %s", tool, strings.Join(Tools, ", "))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s history from table %s: %v", tool, table, err)
+	}
+	return h, nil
+}
+
+// goose_db_version: id, version_id, is_applied, tstamp. the latest row of the version is the status.
+func readGooseHistory(ctx context.Context, db querier, table string, h *History) error {
+	rows, err := db.QueryContext(ctx, "SELECT version_id, is_applied FROM "+table+" ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var versions []string
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		var isApplied bool
+		if err = rows.Scan(&version, &isApplied); err != nil {
+			return err
+		}
+		// version 0 is the initial row created by goose
+		if VersionKey(version) == "" {
+			continue
+		}
+
+		if _, ok := applied[version]; !ok {
+			versions = append(versions, version)
+		}
+		applied[version] = isApplied
+	}
+
+	for _, version := range versions {
+		if applied[version] {
+			h.Applied = append(h.Applied, version)
+		}
+	}
+	return rows.Err()
+}
+
+// schema_migrations: version, dirty. only one row of the current version.
+func readMigrateHistory(ctx context.Context, db querier, table string, h *History) error {
+	rows, err := db.QueryContext(ctx, "SELECT version, dirty FROM "+table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&h.Current, &h.Dirty); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// flyway_schema_history: installed_rank, version, type, success ...
+func readFlywayHistory(ctx context.Context, db querier, table string, h *History) error {
+	rows, err := db.QueryContext(ctx, "SELECT version, type, success FROM "+table+" WHERE version IS NOT NULL ORDER BY installed_rank")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var versions []string
+	status := make(map[string]string)
+	for rows.Next() {
+		var version, typ string
+		var success bool
+		if err = rows.Scan(&version, &typ, &success); err != nil {
+			return err
+		}
+
+		switch {
+		case typ == "BASELINE":
+			h.Current = version
+			continue
+		case strings.HasPrefix(typ, "UNDO_"):
+			if success {
+				status[version] = ""
+			}
+			continue
+		}
+
+		if _, ok := status[version]; !ok {
+			versions = append(versions, version)
+		}
+		if success {
+			status[version] = migration.StatusUp
+		} else {
+			status[version] = migration.StatusDirty
+		}
+	}
+
+	for _, version := range versions {
+		switch status[version] {
+		case migration.StatusUp:
+			h.Applied = append(h.Applied, version)
+		case migration.StatusDirty:
+			h.Failed = append(h.Failed, version)
+		}
+	}
+	return rows.Err()
+}
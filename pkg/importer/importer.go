@@ -0,0 +1,343 @@
+// Package importer converts the migration files and history of other migration tools to miglite.
+//
+// Supported tools:
+//
+//   - goose: {VERSION}_{name}.sql with "-- +goose Up" and "-- +goose Down" annotations
+//   - golang-migrate: {VERSION}_{name}.up.sql and {VERSION}_{name}.down.sql file pairs
+//   - flyway: V{VERSION}__{name}.sql, the undo file U{VERSION}__{name}.sql as DOWN section
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/miglite/internal/migutil"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+// supported migration tools
+const (
+	Goose         = "goose"
+	GolangMigrate = "golang-migrate"
+	Flyway        = "flyway"
+)
+
+// Tools the supported migration tools
+var Tools = []string{Goose, GolangMigrate, Flyway}
+
+// SourceMark the header line of the converted file, records the source tool and version.
+//
+//	-- Import-source: goose@20230101120000
+const SourceMark = "-- Import-source:"
+
+// File is a converted miglite migration file
+type File struct {
+	// Tool name of the source
+	Tool string
+	// Version of the source migration. eg: 20230101120000(goose), 1.2(flyway)
+	Version string
+	// SrcFiles the source file paths
+	SrcFiles []string
+	// FileName the miglite file name. eg: 20251105-102430-create-users.sql
+	FileName string
+	// Contents in miglite format
+	Contents string
+}
+
+// Result of convert the migration files
+type Result struct {
+	Files []*File
+	// Skipped source files are not supported. eg: flyway repeatable migration R__name.sql
+	Skipped []string
+}
+
+// source migration of the tool
+type source struct {
+	version, name string
+	up, down      string
+	files         []string
+	noTx          bool
+	// vars option of the converted migration. see migration.Options.Vars
+	vars string
+}
+
+var (
+	// {VERSION}_{name}.sql
+	gooseFilename = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+	// {VERSION}_{name}.up.sql, {VERSION}_{name}.down.sql
+	migrateFilename = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+	// V{VERSION}__{name}.sql, U{VERSION}__{name}.sql
+	flywayFilename = regexp.MustCompile(`^([VU])(\d+(?:[._]\d+)*)__(.+)\.sql$`)
+	// invalid chars for the miglite file name
+	invalidNameChars = regexp.MustCompile(`[^\w-]+`)
+)
+
+// Convert reads the migration files of the tool in srcDir, and converts them to miglite format.
+//
+// The miglite versions are generated from the startTime, increase 1 second for each migration by the source order.
+//
+//   - driver: the target database driver, for convert the goose StatementBegin...StatementEnd blocks. see parseGoose
+func Convert(tool, srcDir, driver string, startTime time.Time) (*Result, error) {
+	driver = migutil.FmtDriverName(driver)
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	sources := make(map[string]*source)
+	getSource := func(version, name string) *source {
+		key := VersionKey(version)
+		if src, ok := sources[key]; ok {
+			return src
+		}
+		src := &source{version: version, name: name}
+		sources[key] = src
+		return src
+	}
+
+	for _, entry := range entries {
+		fName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fName, ".sql") {
+			continue
+		}
+
+		filePath := filepath.Join(srcDir, fName)
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		switch tool {
+		case Goose:
+			m := gooseFilename.FindStringSubmatch(fName)
+			if m == nil {
+				res.Skipped = append(res.Skipped, filePath)
+				continue
+			}
+			src := getSource(m[1], m[2])
+			src.files = append(src.files, filePath)
+			if err = parseGoose(src, string(contents), driver); err != nil {
+				return nil, fmt.Errorf("%s: %v", filePath, err)
+			}
+		case GolangMigrate:
+			m := migrateFilename.FindStringSubmatch(fName)
+			if m == nil {
+				res.Skipped = append(res.Skipped, filePath)
+				continue
+			}
+			src := getSource(m[1], m[2])
+			src.files = append(src.files, filePath)
+			// golang-migrate does not substitute variables
+			src.vars = migration.VarsOff
+			if m[3] == "up" {
+				src.up = string(contents)
+			} else {
+				src.down = string(contents)
+			}
+		case Flyway:
+			m := flywayFilename.FindStringSubmatch(fName)
+			if m == nil {
+				res.Skipped = append(res.Skipped, filePath)
+				continue
+			}
+			src := getSource(m[2], m[3])
+			src.files = append(src.files, filePath)
+			if m[1] == "V" {
+				src.up = string(contents)
+			} else {
+				src.down = string(contents)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported migration tool %q, allow: %s", tool, strings.Join(Tools, ", "))
+		}
+	}
+
+	list := make([]*source, 0, len(sources))
+	for _, src := range sources {
+		if strings.TrimSpace(src.up) == "" {
+			return nil, fmt.Errorf("no UP migration for the %s version %s: %s", tool, src.version, strings.Join(src.files, ", "))
+		}
+		list = append(list, src)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return CompareVersion(list[i].version, list[j].version) < 0
+	})
+
+	startTime = startTime.Truncate(time.Second)
+	for i, src := range list {
+		name := strings.Trim(invalidNameChars.ReplaceAllString(src.name, "-"), "-")
+		res.Files = append(res.Files, &File{
+			Tool:     tool,
+			Version:  src.version,
+			SrcFiles: src.files,
+			FileName: startTime.Add(time.Duration(i)*time.Second).Format(migration.DateLayout) + "-" + name + ".sql",
+			Contents: src.render(tool),
+		})
+	}
+	return res, nil
+}
+
+// parseGoose parses the goose annotations: Up, Down, StatementBegin, StatementEnd, NO TRANSACTION, ENVSUB
+//
+// The variables are not rendered(vars=off), unless the file has the ENVSUB ON annotation(vars=on).
+//
+// The StatementBegin...StatementEnd block is kept as one statement by the driver:
+//
+//   - mysql: wrap the block by the DELIMITER command, the splitter does not track BEGIN...END
+//   - postgres, sqlite, mssql: keep as is, the dialect splitter handles the dollar quote, trigger block and GO batch
+//   - others: not supported, returns an error
+func parseGoose(src *source, contents, driver string) error {
+	var section string
+	var up, down strings.Builder
+	// goose substitutes the variables only in the ENVSUB ON block
+	src.vars = migration.VarsOff
+	// the StatementBegin block, nil for not in block
+	var block *strings.Builder
+
+	current := func() *strings.Builder {
+		if section == "down" {
+			return &down
+		}
+		return &up
+	}
+
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "-- +goose ") {
+			switch annotation := strings.ToUpper(strings.TrimSpace(trimmed[len("-- +goose "):])); annotation {
+			case "UP":
+				section = "up"
+			case "DOWN":
+				section = "down"
+			case "NO TRANSACTION":
+				src.noTx = true
+			case "STATEMENTBEGIN":
+				if driver != migcom.DriverMySQL && driver != migcom.DriverPostgres && driver != migcom.DriverSQLite && driver != migcom.DriverMSSQL {
+					return fmt.Errorf("the goose StatementBegin block is not supported for driver %q", driver)
+				}
+				if driver == migcom.DriverMySQL {
+					block = &strings.Builder{}
+				}
+			case "STATEMENTEND":
+				if block != nil {
+					// eg: END; => END //
+					body := strings.TrimSuffix(strings.TrimRight(block.String(), " \t\r\n"), ";")
+					current().WriteString("DELIMITER //\n" + body + " //\nDELIMITER ;\n")
+					block = nil
+				}
+			case "ENVSUB ON":
+				src.vars = migration.VarsOn
+			case "ENVSUB OFF":
+				// the default, the vars option is set for whole file
+			default:
+				return fmt.Errorf("unsupported goose annotation %q", trimmed)
+			}
+			continue
+		}
+
+		if block != nil {
+			block.WriteString(line)
+		} else if section != "" {
+			current().WriteString(line)
+		}
+	}
+
+	if section == "" {
+		return fmt.Errorf("missing the '-- +goose Up' annotation")
+	}
+	if block != nil {
+		return fmt.Errorf("missing the '-- +goose StatementEnd' annotation")
+	}
+	src.up, src.down = up.String(), down.String()
+	return nil
+}
+
+// render the miglite migration file contents
+func (src *source) render(tool string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s@%s\n", SourceMark, tool, src.version)
+	for _, file := range src.files {
+		sb.WriteString("-- source file: " + filepath.Base(file) + "\n")
+	}
+	if src.noTx {
+		sb.WriteString(migration.MarkOption + " no-transaction\n")
+	}
+	if src.vars != "" {
+		sb.WriteString(migration.MarkOption + " vars=" + src.vars + "\n")
+	}
+
+	sb.WriteString("\n" + migration.MarkUp + "\n")
+	sb.WriteString(strings.TrimSpace(src.up) + "\n")
+	if down := strings.TrimSpace(src.down); down != "" {
+		sb.WriteString("\n" + migration.MarkDown + "\n")
+		sb.WriteString(down + "\n")
+	}
+	return sb.String()
+}
+
+// ParseSource parses the source tool and version from the converted file contents. see SourceMark
+func ParseSource(contents string) (tool, version string, ok bool) {
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, SourceMark) {
+			tool, version, ok = strings.Cut(strings.TrimSpace(line[len(SourceMark):]), "@")
+			return tool, version, ok && tool != "" && version != ""
+		}
+		// the header comments end
+		if line != "" && !strings.HasPrefix(line, "--") {
+			break
+		}
+	}
+	return "", "", false
+}
+
+// CompareVersion compares the numeric versions of the tools, the parts are separated by '.' or '_'.
+// eg: 1.2 < 1.10, 0001 = 1
+func CompareVersion(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+
+		// compare as number: longer is bigger, then by string
+		if len(x) != len(y) {
+			if len(x) < len(y) {
+				return -1
+			}
+			return 1
+		}
+		if x != y {
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []string {
+	parts := strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' })
+	for i, p := range parts {
+		parts[i] = strings.TrimLeft(p, "0")
+	}
+	// ignore the trailing zero parts. eg: 1.0 = 1
+	for len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	return parts
+}
+
+// VersionKey returns the normalized version for compare equal. eg: 0001 => 1, 1.0 => 1
+func VersionKey(version string) string {
+	return strings.Join(versionParts(version), ".")
+}
@@ -0,0 +1,179 @@
+package importer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/importer"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/migration"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		assert.NoErr(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	return dir
+}
+
+var startTime = time.Date(2025, 11, 5, 10, 24, 30, 0, time.UTC)
+
+func TestConvert_goose(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00002_add_age.sql": `-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose ENVSUB ON
+CREATE INDEX CONCURRENTLY idx_users_age ON users(age);
+`,
+		"00001_create_users.sql": `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (id INT);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE users;
+`,
+		"main.go": "package main",
+	})
+
+	res, err := importer.Convert(importer.Goose, dir, migcom.DriverSQLite, startTime)
+	assert.NoErr(t, err)
+	assert.Len(t, res.Files, 2)
+
+	file := res.Files[0]
+	assert.Eq(t, "00001", file.Version)
+	assert.Eq(t, "20251105-102430-create_users.sql", file.FileName)
+	assert.Eq(t, `-- Import-source: goose@00001
+-- source file: 00001_create_users.sql
+-- Migrate-option: vars=off
+
+-- Migrate:UP
+CREATE TABLE users (id INT);
+
+-- Migrate:DOWN
+DROP TABLE users;
+`, file.Contents)
+
+	file = res.Files[1]
+	assert.Eq(t, "20251105-102431-add_age.sql", file.FileName)
+	assert.StrContains(t, file.Contents, "-- Migrate-option: no-transaction\n-- Migrate-option: vars=on\n")
+
+	// the converted contents is valid
+	mig := &migration.Migration{FileName: file.FileName, Contents: file.Contents}
+	assert.NoErr(t, mig.ParseContents())
+	assert.True(t, mig.Options.NoTransaction)
+	assert.Eq(t, migration.VarsOn, mig.Options.Vars)
+	assert.False(t, mig.HasDown())
+
+	tool, version, ok := importer.ParseSource(file.Contents)
+	assert.True(t, ok)
+	assert.Eq(t, importer.Goose, tool)
+	assert.Eq(t, "00002", version)
+}
+
+func TestConvert_gooseBlock(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00001_add_user_proc.sql": `-- +goose Up
+CREATE TABLE users (id INT, name VARCHAR(64));
+-- +goose StatementBegin
+CREATE PROCEDURE add_user(IN name VARCHAR(64))
+BEGIN
+    INSERT INTO users(name) VALUES (name);
+    SELECT LAST_INSERT_ID();
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP PROCEDURE add_user;
+DROP TABLE users;
+`,
+	})
+
+	res, err := importer.Convert(importer.Goose, dir, migcom.DriverMySQL, startTime)
+	assert.NoErr(t, err)
+	assert.Len(t, res.Files, 1)
+
+	// the block is kept as one statement by the DELIMITER command
+	mig := &migration.Migration{FileName: res.Files[0].FileName, Contents: res.Files[0].Contents, Driver: migcom.DriverMySQL}
+	assert.NoErr(t, mig.ParseContents())
+	statements := mig.Statements(migcom.DriverMySQL, migration.StatusUp)
+	assert.Len(t, statements, 2)
+	assert.Eq(t, "CREATE TABLE users (id INT, name VARCHAR(64))", statements[0].SQL)
+	assert.Eq(t, "CREATE PROCEDURE add_user(IN name VARCHAR(64))\nBEGIN\n    INSERT INTO users(name) VALUES (name);\n    SELECT LAST_INSERT_ID();\nEND", statements[1].SQL)
+	assert.Len(t, mig.Statements(migcom.DriverMySQL, migration.StatusDown), 2)
+
+	// the splitter of unknown driver does not keep the block
+	_, err = importer.Convert(importer.Goose, dir, "oracle", startTime)
+	assert.ErrSubMsg(t, err, `the goose StatementBegin block is not supported for driver "oracle"`)
+}
+
+func TestConvert_golangMigrate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"10_create_posts.up.sql":   "CREATE TABLE posts (id INT);",
+		"10_create_posts.down.sql": "DROP TABLE posts;",
+		"9_create_users.up.sql":    "CREATE TABLE users (id INT);",
+	})
+
+	res, err := importer.Convert(importer.GolangMigrate, dir, "", startTime)
+	assert.NoErr(t, err)
+	assert.Len(t, res.Files, 2)
+	assert.Eq(t, "9", res.Files[0].Version)
+	assert.Eq(t, "10", res.Files[1].Version)
+	assert.Len(t, res.Files[1].SrcFiles, 2)
+	assert.StrContains(t, res.Files[1].Contents, "-- Migrate:DOWN\nDROP TABLE posts;\n")
+	assert.StrContains(t, res.Files[1].Contents, "-- Migrate-option: vars=off\n")
+
+	// missing UP file
+	assert.NoErr(t, os.WriteFile(filepath.Join(dir, "11_orphan.down.sql"), []byte("SELECT 1;"), 0644))
+	_, err = importer.Convert(importer.GolangMigrate, dir, "", startTime)
+	assert.ErrSubMsg(t, err, "no UP migration for the golang-migrate version 11")
+}
+
+func TestConvert_flyway(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"V1__create_users.sql":     "CREATE TABLE users (id INT);",
+		"V1.10__add_index.sql":     "CREATE INDEX idx_users_id ON users(id);",
+		"V1.2__add age column.sql": "ALTER TABLE users ADD age INT;",
+		"U1.2__add age column.sql": "ALTER TABLE users DROP age;",
+		"R__users_view.sql":        "CREATE OR REPLACE VIEW v_users AS SELECT * FROM users;",
+	})
+
+	res, err := importer.Convert(importer.Flyway, dir, "", startTime)
+	assert.NoErr(t, err)
+	assert.Len(t, res.Files, 3)
+	assert.Len(t, res.Skipped, 1)
+	assert.Eq(t, "1", res.Files[0].Version)
+	assert.Eq(t, "1.2", res.Files[1].Version)
+	assert.Eq(t, "20251105-102431-add-age-column.sql", res.Files[1].FileName)
+	assert.StrContains(t, res.Files[1].Contents, "-- Migrate:DOWN\nALTER TABLE users DROP age;\n")
+	assert.Eq(t, "1.10", res.Files[2].Version)
+
+	_, err = importer.Convert("liquibase", dir, "", startTime)
+	assert.ErrSubMsg(t, err, "unsupported migration tool")
+}
+
+func TestCompareVersion(t *testing.T) {
+	assert.Eq(t, 0, importer.CompareVersion("0001", "1"))
+	assert.Eq(t, 0, importer.CompareVersion("1.0", "1"))
+	assert.Eq(t, 0, importer.CompareVersion("1_2", "1.2"))
+	assert.Eq(t, -1, importer.CompareVersion("1.2", "1.10"))
+	assert.Eq(t, -1, importer.CompareVersion("9", "10"))
+	assert.Eq(t, 1, importer.CompareVersion("20230102000000", "20230101000000"))
+}
+
+func TestHistory_Status(t *testing.T) {
+	h := &importer.History{Applied: []string{"5"}, Failed: []string{"6"}}
+	assert.Eq(t, migration.StatusUp, h.Status("0005"))
+	assert.Eq(t, migration.StatusDirty, h.Status("6"))
+	assert.Eq(t, "", h.Status("4"))
+
+	// golang-migrate: all versions before the current are applied
+	h = &importer.History{Current: "3", Dirty: true}
+	assert.Eq(t, migration.StatusUp, h.Status("2"))
+	assert.Eq(t, migration.StatusDirty, h.Status("3"))
+	assert.Eq(t, "", h.Status("4"))
+}
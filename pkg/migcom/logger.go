@@ -3,6 +3,11 @@ package migcom
 import (
 	"fmt"
 	"log"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/gookit/goutil/x/ccolor"
 )
 
 // AppError represents an application-specific error
@@ -44,27 +49,101 @@ type Logger interface {
 }
 
 // Log default Logger instance
-var Log = &DefaultLogger{}
+var Log Logger = &DefaultLogger{}
+
+// PlainMsg formats the message, and removes the color tags and leading emoji icons.
+func PlainMsg(msg string, args ...any) string {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	return strings.TrimLeftFunc(ccolor.ClearTag(msg), func(r rune) bool {
+		return unicode.IsSpace(r) || r > unicode.MaxASCII && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
 
-// DefaultLogger is a simple logger implementation
+// DefaultLogger is a simple logger implementation, output by the std log package.
 type DefaultLogger struct{}
 
 // Debug logs a debug message
 func (l *DefaultLogger) Debug(msg string, args ...any) {
-	log.Printf("[DEBUG] "+msg, args...)
+	log.Print("[DEBUG] " + PlainMsg(msg, args...))
 }
 
 // Info logs an info message
 func (l *DefaultLogger) Info(msg string, args ...any) {
-	log.Printf("[INFO] "+msg, args...)
+	log.Print("[INFO] " + PlainMsg(msg, args...))
 }
 
 // Warn logs a warning message
 func (l *DefaultLogger) Warn(msg string, args ...any) {
-	log.Printf("[WARN] "+msg, args...)
+	log.Print("[WARN] " + PlainMsg(msg, args...))
 }
 
 // Error logs an error message
 func (l *DefaultLogger) Error(msg string, args ...any) {
-	log.Printf("[ERROR] "+msg, args...)
+	log.Print("[ERROR] " + PlainMsg(msg, args...))
+}
+
+// NopLogger is a Logger that discards all messages
+type NopLogger struct{}
+
+// Debug implements Logger
+func (NopLogger) Debug(string, ...any) {}
+
+// Info implements Logger
+func (NopLogger) Info(string, ...any) {}
+
+// Warn implements Logger
+func (NopLogger) Warn(string, ...any) {}
+
+// Error implements Logger
+func (NopLogger) Error(string, ...any) {}
+
+// SlogLogger is a Logger adapter for log/slog
+type SlogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger creates a new SlogLogger. if l is nil, will use slog.Default()
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogLogger{l: l}
+}
+
+// Debug logs a debug message
+func (l *SlogLogger) Debug(msg string, args ...any) { l.l.Debug(PlainMsg(msg, args...)) }
+
+// Info logs an info message
+func (l *SlogLogger) Info(msg string, args ...any) { l.l.Info(PlainMsg(msg, args...)) }
+
+// Warn logs a warning message
+func (l *SlogLogger) Warn(msg string, args ...any) { l.l.Warn(PlainMsg(msg, args...)) }
+
+// Error logs an error message
+func (l *SlogLogger) Error(msg string, args ...any) { l.l.Error(PlainMsg(msg, args...)) }
+
+// ConsoleLogger outputs colored messages to the console. It is used by the CLI commands.
+type ConsoleLogger struct{}
+
+// Debug logs a debug message
+func (l *ConsoleLogger) Debug(msg string, args ...any) {
+	ccolor.Printf("<gray>[DEBUG]</> "+msg+"\n", args...)
+}
+
+// Info logs an info message
+func (l *ConsoleLogger) Info(msg string, args ...any) {
+	ccolor.Printf(msg+"\n", args...)
+}
+
+// Warn logs a warning message
+func (l *ConsoleLogger) Warn(msg string, args ...any) {
+	ccolor.Warnf(msg+"\n", args...)
+}
+
+// Error logs an error message
+func (l *ConsoleLogger) Error(msg string, args ...any) {
+	ccolor.Errorf(msg+"\n", args...)
 }
@@ -0,0 +1,30 @@
+package migcom_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/miglite/pkg/migcom"
+)
+
+func TestPlainMsg(t *testing.T) {
+	assert.Eq(t, "Database connect successful! driver: sqlite", migcom.PlainMsg("✅  Database connect successful! driver: <green>%s</>", "sqlite"))
+	assert.Eq(t, "Discovering migrations", migcom.PlainMsg("🔎  Discovering migrations"))
+	assert.Eq(t, "Skipping ⏭️ file", migcom.PlainMsg("Skipping ⏭️ file"))
+	assert.Eq(t, "100%", migcom.PlainMsg("100%"))
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := migcom.NewSlogLogger(slog.New(slog.NewJSONHandler(buf, nil)))
+
+	logger.Info("✅  Database connect successful! driver: <green>%s</>", "sqlite")
+	logger.Debug("not output on info level")
+	assert.StrContains(t, buf.String(), `"level":"INFO","msg":"Database connect successful! driver: sqlite"`)
+	assert.NotContains(t, buf.String(), "not output")
+
+	var nop migcom.Logger = migcom.NopLogger{}
+	nop.Error("discard %s", "message")
+}
@@ -1,11 +1,18 @@
 package migration
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
 
-	"github.com/gookit/goutil/x/ccolor"
 	"github.com/gookit/miglite/internal/database"
+	"github.com/gookit/miglite/pkg/migcom"
+	"github.com/gookit/miglite/pkg/sqlsplit"
 )
 
 // Executor handles the execution of migrations
@@ -13,43 +20,207 @@ type Executor struct {
 	db *database.DB
 	// verbose flag
 	verbose bool
+	// timeout for execute each migration. 0 is no timeout
+	timeout time.Duration
+	logger  migcom.Logger
+	// hooks for the lifecycle of each migration
+	hooks Hooks
+	// vars for render the template variables in migration SQL. see RenderVars
+	vars map[string]string
+	// version of miglite, save to the record. see Record.MigliteVersion
+	version string
+	// operator the OS user and host of run migrations. see Record.AppliedBy
+	operator string
 	// tracker *Tracker
 }
 
 // NewExecutor creates a new migration executor
 func NewExecutor(db *database.DB, verbose bool) *Executor {
 	return &Executor{
-		db:      db,
-		verbose: verbose,
+		db:       db,
+		verbose:  verbose,
+		logger:   migcom.Log,
+		operator: CurrentOperator(),
 	}
 }
 
+// CurrentOperator returns the OS user and host name. eg: inhere@my-host
+func CurrentOperator() string {
+	var name string
+	if u, err := user.Current(); err == nil {
+		name = filepath.Base(u.Username)
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+// SetLogger sets the logger of the executor
+func (e *Executor) SetLogger(logger migcom.Logger) { e.logger = logger }
+
+// AddHook adds hooks for the lifecycle of each migration
+func (e *Executor) AddHook(hooks ...Hook) { e.hooks = append(e.hooks, hooks...) }
+
+// SetVars sets the template variables for render the migration SQL.
+// The SQL is rendered only when vars is not empty, or the migration has the option vars=on. see RenderVars
+func (e *Executor) SetVars(vars map[string]string) { e.vars = vars }
+
+// SetVersion sets the version of miglite, it will be saved to the applied record.
+func (e *Executor) SetVersion(version string) { e.version = version }
+
+// SetTimeout sets the timeout for execute each migration. 0 is no timeout
+func (e *Executor) SetTimeout(timeout time.Duration) { e.timeout = timeout }
+
 // ExecuteUp executes the UP part of a migration
 func (e *Executor) ExecuteUp(migration *Migration) error {
+	return e.Up(migration).Err
+}
+
+// ExecuteDown executes the DOWN part of a migration
+func (e *Executor) ExecuteDown(migration *Migration) error {
+	res := e.Down(migration)
+	if res.Err == nil {
+		e.logger.Info("Successfully rolled back migration: %s", migration.FileName)
+	}
+	return res.Err
+}
+
+// Up executes the UP part of a migration, returns the run result
+func (e *Executor) Up(migration *Migration) *Result {
+	return e.UpContext(context.Background(), migration)
+}
+
+// UpContext executes the UP part of a migration with context, returns the run result.
+//
+// If the context is canceled or timeout, the transaction will be rolled back.
+func (e *Executor) UpContext(ctx context.Context, migration *Migration) *Result {
+	return e.run(ctx, migration, StatusUp, ResultApplied)
+}
+
+// Down executes the DOWN part of a migration, returns the run result
+func (e *Executor) Down(migration *Migration) *Result {
+	return e.DownContext(context.Background(), migration)
+}
+
+// DownContext executes the DOWN part of a migration with context, returns the run result.
+//
+// If the context is canceled or timeout, the transaction will be rolled back.
+func (e *Executor) DownContext(ctx context.Context, migration *Migration) *Result {
+	return e.run(ctx, migration, StatusDown, ResultRolled)
+}
+
+// DryRun renders the SQL statements of the migration without executing them, the Result.SQL is the rendered statements.
+func (e *Executor) DryRun(migration *Migration, direction string) *Result {
+	res := NewResult(migration, direction)
+	if driver := e.db.Driver(); !migration.Options.AllowDriver(driver) {
+		res.Message = "not for driver " + driver
+		return res.Done(ResultIgnored)
+	}
+	if migration.IsGoCode() {
+		res.Message = "Go-code migration"
+		return res.Done(ResultDryRun)
+	}
+
+	statements, err := e.statements(migration, direction)
+	if err != nil {
+		return res.Fail(err)
+	}
+	for _, st := range statements {
+		res.SQL = append(res.SQL, st.SQL)
+	}
+	return res.Done(ResultDryRun)
+}
+
+func (e *Executor) run(ctx context.Context, migration *Migration, direction, doneStatus string) *Result {
+	res := NewResult(migration, direction)
+	if driver := e.db.Driver(); !migration.Options.AllowDriver(driver) {
+		res.Message = "not for driver " + driver
+		return res.Done(ResultIgnored)
+	}
+
+	if err := e.execute(ctx, migration, direction, res); err != nil {
+		e.hooks.OnError(ctx, migration, direction, err)
+		e.saveFailedHistory(ctx, migration, direction, res.Fail(err))
+		return res
+	}
+	return res.Done(doneStatus)
+}
+
+// saveFailedHistory records the failed event to history, outside the rolled back transaction.
+func (e *Executor) saveFailedHistory(ctx context.Context, migration *Migration, direction string, res *Result) {
+	status := ResultFailed
+	var dirtyErr *DirtyError
+	if errors.As(res.Err, &dirtyErr) {
+		status = StatusDirty
+	}
+
+	// NOTE: the ctx maybe canceled or timeout, still record the event
+	err1 := SaveHistoryContext(context.WithoutCancel(ctx), e.db, e.newHistoryEvent(migration, direction, status, res), nil)
+	if err1 != nil {
+		e.logger.Error("Failed to record history of migration %s: %v", migration.Version, err1)
+	}
+}
+
+// newHistoryEvent creates a history event for the migration
+func (e *Executor) newHistoryEvent(migration *Migration, direction, status string, res *Result) *HistoryEvent {
+	ev := &HistoryEvent{
+		Version:     migration.Version,
+		Action:      direction,
+		Status:      status,
+		Operator:    e.operator,
+		ExecutionMs: time.Since(res.StartedAt).Milliseconds(),
+	}
+	if res.Err != nil {
+		ev.Message = res.Err.Error()
+	}
+	return ev
+}
+
+// execute the UP or DOWN part of a migration in a transaction, and save the record status
+func (e *Executor) execute(ctx context.Context, migration *Migration, direction string, res *Result) (err error) {
+	timeout := e.timeout
+	if migration.Options.Timeout > 0 {
+		timeout = migration.Options.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if migration.Options.NoTransaction {
+		return e.executeNoTx(ctx, migration, direction, res)
+	}
+
 	// Start a transaction
-	tx, err := e.db.Begin()
+	tx, err := e.db.BeginTx(ctx, migration.Options.TxOptions())
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer func() {
 		if err != nil {
-			if err1 := tx.Rollback(); err1 != nil {
-				log.Printf("[ERROR] Failed to rollback transaction: %v", err1)
+			// NOTE: the transaction is already rolled back by database/sql on context canceled.
+			if err1 := tx.Rollback(); err1 != nil && !errors.Is(err1, sql.ErrTxDone) {
+				e.logger.Error("Failed to rollback transaction: %v", err1)
 			}
 		}
 	}()
 
-	if e.verbose {
-		ccolor.Printf("Executing migration UP Section: %s", migration.UpSection)
+	if err = e.hooks.BeforeMigration(ctx, migration, direction, tx); err != nil {
+		return fmt.Errorf("before migration hook: %w", err)
 	}
 
-	// Execute the UP migration
-	if _, err = tx.Exec(migration.UpSection); err != nil {
-		return fmt.Errorf("failed to execute UP migration: %v", err)
+	// Execute the migration section
+	if err = e.executeSection(ctx, tx, migration, direction, res); err != nil {
+		return err
+	}
+	if err = e.hooks.AfterMigration(ctx, migration, direction, tx); err != nil {
+		return fmt.Errorf("after migration hook: %w", err)
 	}
 
 	// Save record the migration status
-	if err = SaveRecord(e.db, migration.Version, StatusUp, tx); err != nil {
+	if err = e.saveRecord(ctx, migration, direction, res, tx); err != nil {
 		return err
 	}
 
@@ -60,40 +231,144 @@ func (e *Executor) ExecuteUp(migration *Migration) error {
 	return nil
 }
 
-// ExecuteDown executes the DOWN part of a migration
-func (e *Executor) ExecuteDown(migration *Migration) error {
-	// Start a transaction
-	tx, err := e.db.Begin()
+// executeNoTx execute the migration statements one by one on a pinned connection, outside a transaction.
+// The status is recorded only after all statements succeed, the hooks will receive a nil tx.
+//
+// If a statement failed after some statements executed, the migration is marked as StatusDirty.
+func (e *Executor) executeNoTx(ctx context.Context, migration *Migration, direction string, res *Result) error {
+	if migration.IsGoCode() {
+		return fmt.Errorf("no-transaction is not supported by Go-code migration: %s", migration.Version)
+	}
+
+	// pin a connection, some statements depend on the session. eg: SET ...
+	conn, err := e.db.Conn(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to get database connection: %v", err)
 	}
-	defer func() {
-		if err != nil {
-			if err1 := tx.Rollback(); err1 != nil {
-				log.Printf("[ERROR] Failed to rollback transaction: %v", err1)
-			}
+	defer conn.Close()
+
+	if err = e.hooks.BeforeMigration(ctx, migration, direction, nil); err != nil {
+		return fmt.Errorf("before migration hook: %w", err)
+	}
+
+	statements, err := e.statements(migration, direction)
+	if err != nil {
+		return err
+	}
+	if err = e.executeStatements(ctx, conn, migration, direction, statements, res); err != nil {
+		if res.Statements > 0 {
+			return e.markDirty(ctx, migration, res.Statements, len(statements), err)
 		}
-	}()
+		return err
+	}
 
-	if e.verbose {
-		ccolor.Printf("Executing migration DOWN Section: %s", migration.DownSection)
+	if err = e.hooks.AfterMigration(ctx, migration, direction, nil); err != nil {
+		err = fmt.Errorf("after migration hook: %w", err)
+		if len(statements) > 0 {
+			return e.markDirty(ctx, migration, len(statements), len(statements), err)
+		}
+		return err
 	}
+	return e.saveRecord(ctx, migration, direction, res, nil)
+}
 
-	// Execute the DOWN migration
-	if _, err = tx.Exec(migration.DownSection); err != nil {
-		return fmt.Errorf("failed to execute DOWN migration: %v", err)
+// saveRecord save the migration status, and the details of the applied migration. eg: checksum, execution_ms
+func (e *Executor) saveRecord(ctx context.Context, migration *Migration, direction string, res *Result, tx *sql.Tx) error {
+	if err := SaveRecordContext(ctx, e.db, migration.Version, direction, tx); err != nil {
+		return err
+	}
+	if direction != StatusUp {
+		return SaveHistoryContext(ctx, e.db, e.newHistoryEvent(migration, direction, ResultRolled, res), tx)
 	}
 
-	// Update record the migration status
-	if err = SaveRecord(e.db, migration.Version, StatusDown, tx); err != nil {
+	err := SaveRecordInfoContext(ctx, e.db, &Record{
+		Version:        migration.Version,
+		Checksum:       migration.Checksum,
+		ExecutionMs:    time.Since(res.StartedAt).Milliseconds(),
+		AppliedBy:      e.operator,
+		MigliteVersion: e.version,
+		Description:    migration.Description,
+		Kind:           migration.Kind(),
+	}, tx)
+	if err != nil {
 		return err
 	}
+	return SaveHistoryContext(ctx, e.db, e.newHistoryEvent(migration, direction, ResultApplied, res), tx)
+}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
+// markDirty record the half-applied migration as StatusDirty, returns a DirtyError
+func (e *Executor) markDirty(ctx context.Context, migration *Migration, executed, total int, err error) error {
+	// NOTE: the ctx maybe canceled or timeout, still record the status
+	if err1 := SaveRecordContext(context.WithoutCancel(ctx), e.db, migration.Version, StatusDirty, nil); err1 != nil {
+		e.logger.Error("Failed to mark migration %s as dirty: %v", migration.Version, err1)
 	}
+	return &DirtyError{Version: migration.Version, Executed: executed, Total: total, Err: err}
+}
+
+func (e *Executor) executeSection(ctx context.Context, tx *sql.Tx, migration *Migration, direction string, res *Result) error {
+	name, fn := sectionOf(migration, direction)
+
+	// Go-code migration
+	if migration.IsGoCode() {
+		if fn == nil {
+			return fmt.Errorf("no %s function for migration: %s", name, migration.Version)
+		}
+		if err := fn(ctx, tx); err != nil {
+			return fmt.Errorf("failed to execute %s migration: %v", name, err)
+		}
+		return nil
+	}
+
+	statements, err := e.statements(migration, direction)
+	if err != nil {
+		return err
+	}
+	return e.executeStatements(ctx, tx, migration, direction, statements, res)
+}
+
+// statements returns the SQL statements of the migration section, render the variables by the vars option.
+// see Options.RenderVars
+func (e *Executor) statements(migration *Migration, direction string) ([]sqlsplit.Statement, error) {
+	if !migration.Options.RenderVars(len(e.vars) > 0) {
+		return migration.Statements(e.db.Driver(), direction), nil
+	}
+	return migration.RenderStatements(e.db.Driver(), direction, e.vars)
+}
+
+// execer is the common interface of sql.DB, sql.Conn and sql.Tx for execute SQL
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// executeStatements execute the SQL statements one by one, the error contains the file:line and index of the failed statement.
+func (e *Executor) executeStatements(ctx context.Context, ex execer, migration *Migration, direction string, statements []sqlsplit.Statement, res *Result) error {
+	name, _ := sectionOf(migration, direction)
+	for i, st := range statements {
+		if e.verbose {
+			e.logger.Debug("Executing migration %s statement %d/%d: %s", name, i+1, len(statements), st.SQL)
+		}
+
+		// the mssql batch can be run N times by "GO N"
+		for j := 0; j < st.Times(); j++ {
+			ret, err := ex.ExecContext(ctx, st.SQL)
+			if err != nil {
+				return fmt.Errorf("failed to execute %s migration at %s:%d (statement %d/%d): %v",
+					name, migration.sourceOf(direction), st.Line, i+1, len(statements), err)
+			}
 
-	log.Printf("Successfully rolled back migration: %s", migration.FileName)
+			res.Statements++
+			if n, err1 := ret.RowsAffected(); err1 == nil {
+				res.RowsAffected += n
+			}
+		}
+	}
 	return nil
 }
+
+// sectionOf returns the section name and Go function of the migration by direction
+func sectionOf(migration *Migration, direction string) (name string, fn MigrateFunc) {
+	if direction == StatusDown {
+		return "DOWN", migration.DownFunc
+	}
+	return "UP", migration.UpFunc
+}
@@ -1,30 +1,39 @@
 package migration
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/user"
+	"path"
 	"path/filepath"
 	"regexp"
-	"runtime"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/gookit/goutil/fsutil"
-	"github.com/gookit/goutil/x/ccolor"
 )
 
 // CreateMigrations creates multi migration file with the specified names
 func CreateMigrations(migrationsDir string, names []string) ([]string, error) {
+	return DefaultScheme.CreateMigrations(migrationsDir, names)
+}
+
+// CreateMigration creates a new migration file with the specified name
+func CreateMigration(migrationsDir, name string) (string, error) {
+	return DefaultScheme.CreateMigration(migrationsDir, name)
+}
+
+// CreateMigrations creates multi migration file with the specified names by the version scheme
+func (s *VersionScheme) CreateMigrations(migrationsDir string, names []string) ([]string, error) {
 	var files []string
 	for _, name := range names {
 		if name == "" || name[0] == '-' {
 			return nil, fmt.Errorf("invalid migration name: %s", name)
 		}
 
-		filePath, err := CreateMigration(migrationsDir, name)
+		filePath, err := s.CreateMigration(migrationsDir, name)
 		if err != nil {
 			return nil, err
 		}
@@ -33,11 +42,18 @@ func CreateMigrations(migrationsDir string, names []string) ([]string, error) {
 	return files, nil
 }
 
-// CreateMigration creates a new migration file with the specified name
-func CreateMigration(migrationsDir, name string) (string, error) {
-	// Generate filename with current timestamp. format: YYYYMMDD-HHMMSS
-	timestamp := time.Now().Format(DateLayout)
-	filename := fmt.Sprintf("%s-%s.sql", timestamp, name)
+// CreateMigration creates a new migration file with the specified name by the version scheme
+func (s *VersionScheme) CreateMigration(migrationsDir, name string) (string, error) {
+	// Generate filename by the version scheme. eg: YYYYMMDD-HHMMSS-{name}.sql, 0001_{name}.sql
+	// The repeatable migration has no version prefix. eg: R-users-view.sql
+	filename := name + ".sql"
+	if !IsRepeatableFile(filename) {
+		var err error
+		if filename, err = s.NextFilename(migrationsDir, name); err != nil {
+			return "", err
+		}
+	}
+	timestamp := s.Now().Format(DateLayout)
 
 	// Full path for the new migration file
 	filePath := filepath.Join(migrationsDir, filename)
@@ -77,84 +93,149 @@ func CreateMigration(migrationsDir, name string) (string, error) {
 	return filePath, nil
 }
 
-// FindMigrations finds all migration files in the specified directory, and returns them sorted by filename prefix
+// FindMigrations finds all migration files in the specified directory, and returns them sorted by filename prefix.
+// The migration declared requires is sorted after its required migrations. see SortByRequires
 //
 //   - migrationsDir: allow multiple directories separated by comma
 func FindMigrations(migrationsDir string, recursive bool) ([]*Migration, error) {
+	return FindMigrationsFS(nil, migrationsDir, recursive)
+}
+
+// FindMigrationsFS finds all migration files in the directory of the fs.FS, and returns them sorted by filename prefix
+//
+//   - fsys: the file system to search, eg: embed.FS. if is nil, will use the OS file system.
+//   - migrationsDir: allow multiple directories separated by comma
+func FindMigrationsFS(fsys fs.FS, migrationsDir string, recursive bool) ([]*Migration, error) {
+	return FindMigrationsWith(fsys, migrationsDir, recursive, nil)
+}
+
+// FindMigrationsWith finds all migration files like FindMigrationsFS, the file names are parsed by the version scheme.
+//
+//   - scheme: if is nil, will use the DefaultScheme.
+func FindMigrationsWith(fsys fs.FS, migrationsDir string, recursive bool, scheme *VersionScheme) ([]*Migration, error) {
 	var migrations []*Migration
-	ccolor.Printf("🔎  Discovering migrations from <green>%s</>\n", migrationsDir)
 
 	dirPaths := strings.Split(migrationsDir, ",")
 	for _, dirPath := range dirPaths {
-		migList, err := findMigrations(dirPath, recursive)
+		migList, err := findMigrations(fsys, fsPath(fsys, dirPath), recursive, scheme)
 		if err != nil {
 			return nil, err
 		}
 		migrations = append(migrations, migList...)
 	}
 
-	// Sort migrations by timestamp
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].IsBefore(migrations[j])
-	})
-
-	return migrations, nil
+	// Sort migrations by the requires and timestamp
+	return SortByRequires(migrations)
 }
-func findMigrations(dirPath string, recursive bool) ([]*Migration, error) {
+
+func findMigrations(fsys fs.FS, dirPath string, recursive bool, scheme *VersionScheme) ([]*Migration, error) {
 	var migrations []*Migration
+	readFs := orOSFS(fsys)
 
 	// 禁用递归：只查找当前目录的sql文件
 	if !recursive {
-		err := fsutil.FindInDir(dirPath, func(path string, d fs.DirEntry) error {
+		entries, err := fs.ReadDir(readFs, dirPath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil // ignore not exists dir
+			}
+			return nil, fmt.Errorf("failed to read migrations dir %s: %w", dirPath, err)
+		}
+
+		for _, d := range entries {
 			if d.IsDir() {
-				return nil
+				continue
 			}
 
 			// Only process .sql files
 			fName := d.Name()
 			if fName[0] != '_' && strings.HasSuffix(fName, ".sql") {
-				migration, err := NewMigration(path)
+				migration, err := newMigrationFromFile(fsys, path.Join(dirPath, fName), scheme)
 				if err != nil {
-					return err
+					return nil, err
+				}
+				if migration != nil {
+					migrations = append(migrations, migration)
 				}
-				migrations = append(migrations, migration)
 			}
-			return nil
-		})
-		return migrations, err
-	}
-
-	// 以下划线开头的目录会被忽略 eg: _backup/xx.sql
-	ignorePart := "/_"
-	if runtime.GOOS == "windows" {
-		ignorePart = "\\_"
+		}
+		return migrations, nil
 	}
 
-	// filepath.Walk/WalkDir 会递归的遍历子目录
-	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+	// fs.WalkDir 会递归的遍历子目录
+	err := fs.WalkDir(readFs, dirPath, func(filePath string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
 			return err
 		}
 
-		// 忽略掉 _ 开头的目录/文件
-		if strings.Contains(path, ignorePart) {
+		// 忽略掉 _ 开头的目录/文件 eg: _backup/xx.sql
+		if strings.Contains(strings.TrimPrefix(filePath, path.Clean(dirPath)), "/_") {
 			return nil
 		}
 
 		// Only process .sql files
 		fName := d.Name()
 		if fName[0] != '_' && strings.HasSuffix(fName, ".sql") {
-			migration, err := NewMigration(path)
+			migration, err := newMigrationFromFile(fsys, filePath, scheme)
 			if err != nil {
 				return err
 			}
-			migrations = append(migrations, migration)
+			if migration != nil {
+				migrations = append(migrations, migration)
+			}
 		}
 		return nil
 	})
 	return migrations, err
 }
 
+// newMigrationFromFile creates the migration from the found file.
+// The .down.sql file is loaded with its .up.sql file, so returns nil for it.
+func newMigrationFromFile(fsys fs.FS, filePath string, scheme *VersionScheme) (*Migration, error) {
+	if !strings.HasSuffix(filePath, DownFileSuffix) {
+		return NewMigrationWith(fsys, filePath, scheme)
+	}
+
+	upPath := strings.TrimSuffix(filePath, DownFileSuffix) + UpFileSuffix
+	if _, err := fs.Stat(orOSFS(fsys), upPath); err != nil {
+		return nil, fmt.Errorf("missing the UP file %s for migration file: %s", path.Base(upPath), filePath)
+	}
+	return nil, nil
+}
+
+// osFS is a fs.FS for the OS file system. Unlike os.DirFS, it allows relative paths like ../migrations
+type osFS struct{}
+
+// Open opens the named file
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+// ReadFile reads the named file
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// ReadDir reads the named directory
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+func orOSFS(fsys fs.FS) fs.FS {
+	if fsys == nil {
+		return osFS{}
+	}
+	return fsys
+}
+
+// fsPath formats the path for the fs.FS. eg: "./migrations/" => "migrations"
+func fsPath(fsys fs.FS, dirPath string) string {
+	dirPath = strings.TrimSpace(dirPath)
+	if fsys == nil {
+		return filepath.ToSlash(dirPath)
+	}
+
+	dirPath = strings.TrimLeft(path.Clean(filepath.ToSlash(dirPath)), "/")
+	if dirPath == "" {
+		return "."
+	}
+	return dirPath
+}
+
 // defines the regex pattern for extracting the date prefix from a filename
 //
 // format: YYYYMMDD-NNNNNN-{name}.sql
@@ -170,13 +251,79 @@ type FilenameInfo struct {
 	Name string    // eg: add-age-index
 }
 
-// parseFilename extracts the time,name from a migration filename
+// defines the regex pattern for the golang-migrate style version of the UP/DOWN file pair.
+//
+// format: {NUMBER}_{name}. eg: 000001_create_users, 20230101120000_create_users
+var regexPairVersion = regexp.MustCompile(`^(\d+)_([\w-]+)$`)
+
+// parsePairFilename extracts the version and time,name from a .up.sql or .down.sql filename.
+// The version can be formats: YYYYMMDD-NNNNNN-{name}, {NUMBER}_{name}
+//
+//	eg: 000001_create_users.up.sql
+//	=> version: 000001_create_users, {Date: "00000000000000000001", Name: "create_users"}
+func parsePairFilename(filename string) (string, *FilenameInfo, error) {
+	version := strings.TrimSuffix(strings.TrimSuffix(filename, UpFileSuffix), DownFileSuffix)
+	if fi, err := parseVersion(version); err == nil {
+		return version, fi, nil
+	}
+
+	matches := regexPairVersion.FindStringSubmatch(version)
+	if len(matches) < 3 {
+		return "", nil, fmt.Errorf("invalid filename format: %s, expected {NUMBER}_{name}%s or %s-{name}%s", filename, UpFileSuffix, PrefixFormat, UpFileSuffix)
+	}
+
+	return version, newNumberInfo(matches[1], matches[2]), nil
+}
+
+// newNumberInfo creates the info for the {NUMBER}_{name} version.
+// The number can be a timestamp YYYYMMDDHHMMSS, or a sequential number.
+func newNumberInfo(num, name string) *FilenameInfo {
+	fi := &FilenameInfo{Name: name}
+	if t, err := time.Parse("20060102150405", num); err == nil && len(num) == 14 {
+		fi.Time, fi.Date = t, t.Format(DateLayout)
+	} else {
+		fi.Date = padSequence(num)
+	}
+	return fi
+}
+
+// defines the regex pattern for a Go-code migration version
+//
+// format: YYYYMMDD-NNNNNN-{name}, YYYYMMDD-NNNNNN_{name}
+var regexVersion = regexp.MustCompile(`^(\d{8})-(\d{6})[-_]([\w-]+)$`)
+
+// parseFilename extracts the time,name from a migration filename. formats: YYYYMMDD-NNNNNN-{name}.sql, NNNN_{name}.sql
 func parseFilename(filename string) (*FilenameInfo, error) {
 	matches := regexFilename.FindStringSubmatch(filename)
 	if len(matches) < 3 {
-		return nil, fmt.Errorf("invalid filename format: %s, expected %s-{name}.sql", filename, PrefixFormat)
+		// number version. eg: 0001_create_users.sql, 20230101120000_create_users.sql
+		if m := regexPairVersion.FindStringSubmatch(strings.TrimSuffix(filename, ".sql")); m != nil && strings.HasSuffix(filename, ".sql") {
+			return newNumberInfo(m[1], m[2]), nil
+		}
+		return nil, fmt.Errorf("invalid filename format: %s, expected %s-{name}.sql or NNNN_{name}.sql", filename, PrefixFormat)
 	}
+	return newFilenameInfo(filename, matches)
+}
+
+// padSequence pads zero to the sequential number for compare as string
+func padSequence(num string) string {
+	num = strings.TrimLeft(num, "0")
+	if len(num) < 20 {
+		num = strings.Repeat("0", 20-len(num)) + num
+	}
+	return num
+}
+
+// parseVersion extracts the time,name from a Go-code migration version
+func parseVersion(version string) (*FilenameInfo, error) {
+	matches := regexVersion.FindStringSubmatch(version)
+	if len(matches) < 3 {
+		return nil, fmt.Errorf("invalid migration version: %s, expected %s-{name}", version, PrefixFormat)
+	}
+	return newFilenameInfo(version, matches)
+}
 
+func newFilenameInfo(filename string, matches []string) (*FilenameInfo, error) {
 	dateStr := matches[1] + "-" + matches[2]
 	createTime, err := time.Parse(DateLayout, dateStr)
 	if err != nil {
@@ -2,7 +2,10 @@ package migration
 
 import (
 	"os"
+	"regexp"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/gookit/goutil/dump"
 	"github.com/gookit/goutil/testutil/assert"
@@ -27,6 +30,17 @@ func TestParseFilename(t *testing.T) {
 	assert.Err(t, err)
 	assert.Nil(t, fi)
 
+	// number version, the timestamp is parsed same as the .up.sql file
+	fi, err = parseFilename("20230101120000_create_users.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, "20230101-120000", fi.Date)
+	assert.Eq(t, "2023-01-01 12:00:00", fi.Time.Format("2006-01-02 15:04:05"))
+	assert.Eq(t, "create_users", fi.Name)
+
+	fi, err = parseFilename("0012_create_users.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, "00000000000000000012", fi.Date)
+
 	// invalid format
 	fi, err = parseFilename("20251105-add-age-index")
 	assert.Err(t, err)
@@ -73,3 +87,246 @@ func TestCreateMigration(t *testing.T) {
 		dump.P(migrations)
 	})
 }
+
+func TestFindMigrationsFS(t *testing.T) {
+	sqlData := &fstest.MapFile{Data: testContents}
+	fsys := fstest.MapFS{
+		"migrations/20251105-102325-create-users-table.sql":  sqlData,
+		"migrations/sub/20251106-215850-add-age-index.sql":   sqlData,
+		"migrations/_backup/20251101-102325-old-users.sql":   sqlData,
+		"migrations/_20251102-102325-ignored.sql":            sqlData,
+		"migrations/README.md":                               {Data: []byte("docs")},
+		"migrations2/20251104-102325-create-posts-table.sql": sqlData,
+	}
+
+	migrations, err := FindMigrationsFS(fsys, "./migrations/,migrations2", true)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 3)
+	assert.Eq(t, "20251104-102325-create-posts-table.sql", migrations[0].FileName)
+	assert.Eq(t, "migrations/sub/20251106-215850-add-age-index.sql", migrations[2].FilePath)
+
+	// parse contents from fs
+	assert.NoErr(t, migrations[0].Parse())
+	assert.Eq(t, "ALTER TABLE users\n    ADD COLUMN password_hash TEXT;", migrations[0].UpSection)
+
+	migrations, err = FindMigrationsFS(fsys, "migrations", false)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 1)
+
+	// not exists dir is ignored, other read errors are returned
+	migrations, err = FindMigrationsFS(fsys, "not-exists", false)
+	assert.NoErr(t, err)
+	assert.Empty(t, migrations)
+	_, err = FindMigrationsFS(fsys, "migrations/README.md", false)
+	assert.ErrSubMsg(t, err, "failed to read migrations dir migrations/README.md")
+
+	t.Run("MigrationsFromFS", func(t *testing.T) {
+		migs, err := MigrationsFromFS(fsys, "migrations2,migrations/sub", []string{"20251106-215850-add-age-index"})
+		assert.NoErr(t, err)
+		assert.Len(t, migs, 1)
+		assert.Eq(t, "migrations/sub/20251106-215850-add-age-index.sql", migs[0].FilePath)
+
+		_, err = MigrationsFromFS(fsys, "migrations", []string{"20251101-102325-not-exists.sql"})
+		assert.Err(t, err)
+	})
+}
+
+func TestParsePairFilename(t *testing.T) {
+	version, fi, err := parsePairFilename("000012_create_users.up.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, "000012_create_users", version)
+	assert.Eq(t, "00000000000000000012", fi.Date)
+	assert.Eq(t, "create_users", fi.Name)
+	assert.True(t, fi.Time.IsZero())
+
+	version, fi, err = parsePairFilename("20230101120000_create_users.down.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, "20230101120000_create_users", version)
+	assert.Eq(t, "20230101-120000", fi.Date)
+	assert.Eq(t, "2023-01-01 12:00:00", fi.Time.Format("2006-01-02 15:04:05"))
+
+	version, fi, err = parsePairFilename("20251105-102430-add-age-index.up.sql")
+	assert.NoErr(t, err)
+	assert.Eq(t, "20251105-102430-add-age-index", version)
+	assert.Eq(t, "20251105-102430", fi.Date)
+
+	_, _, err = parsePairFilename("create_users.up.sql")
+	assert.Err(t, err)
+}
+
+func TestFindMigrationsFS_filePair(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/2_add_age.up.sql":         {Data: []byte("-- Migrate-option: timeout=5s\nALTER TABLE users ADD COLUMN age INT;")},
+		"migrations/1_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id INT);\n")},
+		"migrations/1_create_users.down.sql":  {Data: []byte("\n-- drop it\nDROP TABLE users;\n")},
+		"migrations/10_create_posts.up.sql":   {Data: []byte("CREATE TABLE posts (id INT);")},
+		"migrations/10_create_posts.down.sql": {Data: []byte("DROP TABLE posts;")},
+	}
+
+	migrations, err := FindMigrationsFS(fsys, "migrations", true)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 3)
+	assert.Eq(t, "1_create_users", migrations[0].Version)
+	assert.Eq(t, "2_add_age", migrations[1].Version)
+	assert.Eq(t, "10_create_posts", migrations[2].Version)
+	assert.Eq(t, "migrations/1_create_users.down.sql", migrations[0].DownFilePath)
+	assert.Eq(t, "", migrations[1].DownFilePath)
+
+	mig := migrations[0]
+	assert.True(t, mig.IsFilePair())
+	assert.NoErr(t, mig.Parse())
+	assert.Eq(t, "CREATE TABLE users (id INT);", mig.UpSection)
+	assert.Eq(t, "-- drop it\nDROP TABLE users;", mig.DownSection)
+	assert.Eq(t, 3, mig.Statements("sqlite", StatusDown)[0].Line)
+	checksum := mig.Checksum
+	assert.Len(t, checksum, 64)
+
+	// moving text across the UP/DOWN files changes the checksum
+	fsys["migrations/1_create_users.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE users (id INT);\n\n")}
+	fsys["migrations/1_create_users.down.sql"] = &fstest.MapFile{Data: []byte("-- drop it\nDROP TABLE users;\n")}
+	assert.NoErr(t, mig.Parse())
+	assert.NotEq(t, checksum, mig.Checksum)
+	assert.Eq(t, checksumOf("CREATE TABLE users (id INT);\n\n", "-- drop it\nDROP TABLE users;\n"), mig.Checksum)
+
+	mig = migrations[1]
+	assert.NoErr(t, mig.Parse())
+	assert.False(t, mig.HasDown())
+	assert.Eq(t, "5s", mig.Options.Timeout.String())
+
+	// find by version
+	migs, err := MigrationsFromFS(fsys, "migrations", []string{"10_create_posts"})
+	assert.NoErr(t, err)
+	assert.Len(t, migs, 1)
+	assert.Eq(t, "migrations/10_create_posts.up.sql", migs[0].FilePath)
+
+	// missing the UP file
+	fsys["migrations/3_orphan.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	_, err = FindMigrationsFS(fsys, "migrations", false)
+	assert.ErrSubMsg(t, err, "missing the UP file 3_orphan.up.sql")
+}
+
+func TestParseVersionScheme(t *testing.T) {
+	s, err := ParseVersionScheme("")
+	assert.NoErr(t, err)
+	assert.Eq(t, SchemeTimestampLocal, s.Name)
+	assert.Eq(t, DateLayout, s.Layout)
+
+	s, err = ParseVersionScheme("timestamp-utc:20060102150405")
+	assert.NoErr(t, err)
+	assert.Eq(t, SchemeTimestampUTC, s.Name)
+	assert.Eq(t, "20060102150405", s.Layout)
+
+	s, err = ParseVersionScheme("sequential:6")
+	assert.NoErr(t, err)
+	assert.True(t, s.IsSequential())
+	assert.Eq(t, 6, s.Digits)
+
+	_, err = ParseVersionScheme("sequential:abc")
+	assert.Err(t, err)
+	_, err = ParseVersionScheme("semver")
+	assert.ErrSubMsg(t, err, "unknown version scheme")
+}
+
+func TestVersionScheme_CreateMigrations(t *testing.T) {
+	t.Run("sequential", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoErr(t, os.MkdirAll(dir+"/sub", 0755))
+		assert.NoErr(t, os.WriteFile(dir+"/sub/0007_old.up.sql", []byte("SELECT 1;"), 0644))
+
+		s, err := ParseVersionScheme(SchemeSequential)
+		assert.NoErr(t, err)
+		files, err := s.CreateMigrations(dir, []string{"create-users", "add-age"})
+		assert.NoErr(t, err)
+		assert.StrContains(t, files[0], "0008_create-users.sql")
+		assert.StrContains(t, files[1], "0009_add-age.sql")
+
+		// repeatable migration has no version prefix
+		file, err := s.CreateMigration(dir, "R-users-view")
+		assert.NoErr(t, err)
+		assert.StrContains(t, file, string(os.PathSeparator)+"R-users-view.sql")
+		assert.NoErr(t, os.Remove(file))
+
+		migrations, err := FindMigrationsWith(nil, dir, true, s)
+		assert.NoErr(t, err)
+		assert.Len(t, migrations, 3)
+		assert.Eq(t, "0007_old", migrations[0].Version)
+		assert.Eq(t, "0009_add-age.sql", migrations[2].Version)
+
+		// find the last number in all the paths
+		other := t.TempDir()
+		assert.NoErr(t, os.WriteFile(other+"/0012_other.sql", []byte("SELECT 1;"), 0644))
+		s.Paths = []string{dir, other}
+		file, err = s.CreateMigration(dir, "add-name")
+		assert.NoErr(t, err)
+		assert.StrContains(t, file, "0013_add-name.sql")
+	})
+
+	t.Run("timestamp in same second", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := ParseVersionScheme(SchemeTimestampUTC)
+		assert.NoErr(t, err)
+		files, err := s.CreateMigrations(dir, []string{"a", "b", "c"})
+		assert.NoErr(t, err)
+
+		// created_at is UTC time too
+		content, err := os.ReadFile(files[0])
+		assert.NoErr(t, err)
+		assert.StrContains(t, string(content), "-- created_at: "+time.Now().UTC().Format(DayLayout))
+
+		migrations, err := FindMigrations(dir, false)
+		assert.NoErr(t, err)
+		assert.Len(t, migrations, 3)
+		for i, mig := range migrations {
+			assert.StrContains(t, files[i], mig.FileName)
+			if i > 0 {
+				assert.NotEq(t, migrations[i-1].SortKey, mig.SortKey)
+			}
+		}
+	})
+
+	t.Run("custom layout", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := ParseVersionScheme("timestamp-utc:20060102150405")
+		assert.NoErr(t, err)
+		files, err := s.CreateMigrations(dir, []string{"create-users"})
+		assert.NoErr(t, err)
+		assert.True(t, regexp.MustCompile(`/\d{14}-create-users\.sql$`).MatchString(files[0]))
+
+		migrations, err := FindMigrationsWith(nil, dir, false, s)
+		assert.NoErr(t, err)
+		assert.Len(t, migrations, 1)
+		assert.Eq(t, migrations[0].Timestamp.Format(DateLayout), migrations[0].SortKey)
+
+		// the default scheme cannot parse it
+		_, err = FindMigrations(dir, false)
+		assert.Err(t, err)
+	})
+}
+
+func TestFindMigrationsFS_repeatable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/R-users-view.sql":                 {Data: []byte("-- Migrate:UP\nCREATE VIEW v_users AS SELECT 1;")},
+		"migrations/R__orders_view.sql":               {Data: []byte("-- Migrate:UP\nCREATE VIEW v_orders AS SELECT 1;")},
+		"migrations/20251105-102430-create-users.sql": {Data: []byte("-- Migrate:UP\nCREATE TABLE users(id INT);")},
+		"migrations/0001_create-roles.sql":            {Data: []byte("-- Migrate:UP\nCREATE TABLE roles(id INT);")},
+	}
+
+	migrations, err := FindMigrationsFS(fsys, "migrations", false)
+	assert.NoErr(t, err)
+	assert.Len(t, migrations, 4)
+	assert.False(t, migrations[0].Repeatable)
+	assert.False(t, migrations[1].Repeatable)
+	assert.Eq(t, "R-users-view.sql", migrations[2].Version)
+	assert.Eq(t, "R__orders_view.sql", migrations[3].Version)
+	assert.True(t, migrations[3].Repeatable)
+
+	mig := migrations[2]
+	assert.Empty(t, mig.Checksum)
+	assert.NoErr(t, mig.Parse())
+	assert.Len(t, mig.Checksum, 64)
+	checksum := mig.Checksum
+
+	fsys["migrations/R-users-view.sql"] = &fstest.MapFile{Data: []byte("-- Migrate:UP\nCREATE VIEW v_users AS SELECT 2;")}
+	assert.NoErr(t, mig.Parse())
+	assert.NotEq(t, checksum, mig.Checksum)
+}
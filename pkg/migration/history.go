@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/goutil/x/stdio"
+	"github.com/gookit/miglite/internal/database"
+)
+
+// HistoryEvent is an event in the append-only history table. Every up, down, skip and failure is recorded.
+type HistoryEvent struct {
+	ID      int64  `db:"id"`
+	Version string `db:"version"`
+	// Action of the event: up, down, skip
+	Action string `db:"action"`
+	// Status of the event: applied, rolled, skipped, failed, dirty. see ResultApplied
+	Status string `db:"status"`
+	// Message the error message on failed
+	Message string `db:"message"`
+	// Operator the OS user and host. eg: inhere@my-host
+	Operator    string    `db:"operator"`
+	ExecutionMs int64     `db:"execution_ms"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// SaveHistoryContext appends an event to the history table. The CreatedAt is set to now if it is zero.
+func SaveHistoryContext(ctx context.Context, db *database.DB, ev *HistoryEvent, tx *sql.Tx) error {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return err
+	}
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = time.Now()
+	}
+
+	aSql := provide.InsertHistory(db.QuoteTable(db.HistoryTableName()))
+	args := []any{ev.Version, ev.Action, ev.Status, ev.Message, ev.Operator, ev.ExecutionMs, ev.CreatedAt}
+	if tx == nil {
+		_, err = db.ExecContext(ctx, aSql, args...)
+	} else {
+		_, err = tx.ExecContext(ctx, aSql, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration history: %v", err)
+	}
+	return nil
+}
+
+// GetHistory retrieves the history events matched the filter, sorted by ID.
+func GetHistory(db *database.DB, filter HistoryFilter) ([]HistoryEvent, error) {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(provide.QueryHistory(db.QuoteTable(db.HistoryTableName())))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migration history: %v", err)
+	}
+	defer stdio.SafeClose(rows)
+
+	var events []HistoryEvent
+	for rows.Next() {
+		var ev HistoryEvent
+		var message, operator sql.NullString
+		var executionMs sql.NullInt64
+		err = rows.Scan(&ev.ID, &ev.Version, &ev.Action, &ev.Status, &message, &operator, &executionMs, &ev.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan migration history: %v", err)
+		}
+
+		ev.Message, ev.Operator, ev.ExecutionMs = message.String, operator.String, executionMs.Int64
+		if filter.Match(&ev) {
+			events = append(events, ev)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating migration history rows: %v", err)
+	}
+
+	// keep the latest events
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+	return events, nil
+}
+
+// HistoryFilter for filter the history events. empty field is not filtered.
+type HistoryFilter struct {
+	// Version contains the keyword
+	Version string
+	// Status or Action of the event. eg: failed, up
+	Status string
+	// Since, Until the time range of the event, include the bounds.
+	Since, Until time.Time
+	// Limit the number of the latest events
+	Limit int
+}
+
+// Match checks the event is matched the filter
+func (f *HistoryFilter) Match(ev *HistoryEvent) bool {
+	if f.Version != "" && !strings.Contains(ev.Version, f.Version) {
+		return false
+	}
+	if f.Status != "" && f.Status != ev.Status && f.Status != ev.Action {
+		return false
+	}
+	if !f.Since.IsZero() && ev.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || !ev.CreatedAt.After(f.Until)
+}
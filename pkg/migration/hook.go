@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Hook for the lifecycle of run migrations.
+//
+// BeforeMigration and AfterMigration are called in the transaction of the migration,
+// return an error will veto the migration and roll back the transaction.
+// For the no-transaction migration, the tx is nil.
+type Hook interface {
+	// BeforeAll is called before run the migrations. direction: up, down.
+	// return an error will stop run the migrations.
+	BeforeAll(ctx context.Context, direction string) error
+	// BeforeMigration is called before execute the migration
+	BeforeMigration(ctx context.Context, mig *Migration, direction string, tx *sql.Tx) error
+	// AfterMigration is called after the migration executed, before commit the transaction
+	AfterMigration(ctx context.Context, mig *Migration, direction string, tx *sql.Tx) error
+	// OnError is called when execute the migration failed, the transaction is rolled back.
+	OnError(ctx context.Context, mig *Migration, direction string, err error)
+	// AfterAll is called after all migrations are processed, include failed.
+	AfterAll(ctx context.Context, report *RunReport)
+}
+
+// NopHook is a Hook that does nothing. It can be embedded to implement only the needed methods.
+type NopHook struct{}
+
+// BeforeAll implements Hook
+func (NopHook) BeforeAll(context.Context, string) error { return nil }
+
+// BeforeMigration implements Hook
+func (NopHook) BeforeMigration(context.Context, *Migration, string, *sql.Tx) error { return nil }
+
+// AfterMigration implements Hook
+func (NopHook) AfterMigration(context.Context, *Migration, string, *sql.Tx) error { return nil }
+
+// OnError implements Hook
+func (NopHook) OnError(context.Context, *Migration, string, error) {}
+
+// AfterAll implements Hook
+func (NopHook) AfterAll(context.Context, *RunReport) {}
+
+// Hooks is a list of Hook, call them in order. It also implements Hook.
+type Hooks []Hook
+
+// BeforeAll implements Hook, stop on the first error
+func (hs Hooks) BeforeAll(ctx context.Context, direction string) error {
+	for _, h := range hs {
+		if err := h.BeforeAll(ctx, direction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BeforeMigration implements Hook, stop on the first error
+func (hs Hooks) BeforeMigration(ctx context.Context, mig *Migration, direction string, tx *sql.Tx) error {
+	for _, h := range hs {
+		if err := h.BeforeMigration(ctx, mig, direction, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AfterMigration implements Hook, stop on the first error
+func (hs Hooks) AfterMigration(ctx context.Context, mig *Migration, direction string, tx *sql.Tx) error {
+	for _, h := range hs {
+		if err := h.AfterMigration(ctx, mig, direction, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnError implements Hook
+func (hs Hooks) OnError(ctx context.Context, mig *Migration, direction string, err error) {
+	for _, h := range hs {
+		h.OnError(ctx, mig, direction, err)
+	}
+}
+
+// AfterAll implements Hook
+func (hs Hooks) AfterAll(ctx context.Context, report *RunReport) {
+	for _, h := range hs {
+		h.AfterAll(ctx, report)
+	}
+}
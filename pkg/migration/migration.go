@@ -1,19 +1,25 @@
 package migration
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"os"
+	"io/fs"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/miglite/internal/migutil"
+	"github.com/gookit/miglite/pkg/sqlsplit"
 )
 
 // Migration represents a single migration file
 type Migration struct {
 	FileName string // filename as version
 	FilePath string // full path
+	// DownFilePath the .down.sql file path of the UP/DOWN file pair, empty if not exists. see IsFilePair
+	DownFilePath string
 	// Contents of migration file
 	Contents string
 	// time from filename
@@ -22,10 +28,33 @@ type Migration struct {
 	SortKey string
 	// Version same as filename
 	Version string
+	// Description the name part of the version. eg: add-age-index
+	Description string
 	// UpSection UP section contents
 	UpSection   string
 	DownSection string
-	// Options TODO options for current migration
+	// UpFunc, DownFunc for Go-code migration. see NewGoMigration
+	UpFunc   MigrateFunc
+	DownFunc MigrateFunc
+	// Repeatable migration runs after the versioned migrations, and runs again when the Checksum changed.
+	// eg: R-users-view.sql. see IsRepeatableFile
+	Repeatable bool
+	// Checksum sha256 of the migration file contents, it is set by Parse.
+	Checksum string
+	// Driver the database driver for select the driver-specific sections. see ParseContents
+	Driver string
+	// Options for current migration. parsed from the header lines: -- Migrate-option:OPTION=VALUE,...
+	Options Options
+	// Requires versions of the migrations that must run before this one. parsed from the header lines: -- requires: VERSION,...
+	// For Go-code migration, set it by MigrationFn. see SortByRequires
+	Requires []string
+
+	// fsys the file system of the migration file. nil for OS file system.
+	fsys fs.FS
+	// upLine, downLine the start line number in file of UpSection, DownSection
+	upLine, downLine int
+	// requiresLoaded mark the Requires has been loaded from the file. see LoadRequires
+	requiresLoaded bool
 }
 
 // ParseFile parses a migration file to extract UP and DOWN sections
@@ -43,41 +72,48 @@ func ParseFile(filePath string) (*Migration, error) {
 
 // MigrationsFrom creates migrations from a list of file names
 func MigrationsFrom(migPath string, files []string) ([]*Migration, error) {
+	return MigrationsFromFS(nil, migPath, files)
+}
+
+// MigrationsFromFS creates migrations from a list of file names in the fs.FS. if fsys is nil, will use the OS file system.
+//
+//   - migPath: allow multiple directories separated by comma
+func MigrationsFromFS(fsys fs.FS, migPath string, files []string) ([]*Migration, error) {
+	return MigrationsFromWith(fsys, migPath, files, nil)
+}
+
+// MigrationsFromWith creates migrations like MigrationsFromFS, the file names are parsed by the version scheme.
+func MigrationsFromWith(fsys fs.FS, migPath string, files []string, scheme *VersionScheme) ([]*Migration, error) {
 	migrations := make([]*Migration, 0, len(files))
-	var migPaths []string
-	if strings.Contains(migPath, ",") {
-		migPaths = strings.Split(migPath, ",")
-	} else {
-		migPaths = []string{migPath}
-	}
+	migPaths := strings.Split(migPath, ",")
+	readFs := orOSFS(fsys)
 
 	for _, file := range files {
 		var filePath string
 		var fileExists bool
-		// check and auto append .sql
+		// check and auto append .sql or .up.sql
+		names := []string{file}
 		if !strings.HasSuffix(file, ".sql") {
-			file = file + ".sql"
+			names = []string{file + ".sql", file + UpFileSuffix}
+			file = names[0]
 		}
 
-		// is multiple paths
-		if len(migPaths) > 0 {
-			for _, dirPath := range migPaths {
-				if fsutil.IsFile(dirPath + "/" + file) {
-					filePath = dirPath + "/" + file
+	findFile:
+		for _, dirPath := range migPaths {
+			for _, name := range names {
+				filePath = path.Join(fsPath(fsys, dirPath), name)
+				if fi, err := fs.Stat(readFs, filePath); err == nil && !fi.IsDir() {
 					fileExists = true
-					break
+					break findFile
 				}
 			}
-		} else {
-			filePath = migPath + "/" + file
-			fileExists = fsutil.IsFile(filePath)
 		}
 
 		if !fileExists {
 			return nil, fmt.Errorf("migration file not exists: %s", file)
 		}
 
-		mig, err := NewMigration(filePath)
+		mig, err := NewMigrationWith(fsys, filePath, scheme)
 		if err != nil {
 			return nil, err
 		}
@@ -89,98 +125,423 @@ func MigrationsFrom(migPath string, files []string) ([]*Migration, error) {
 
 // NewMigration creates a new Migration instance from a file path
 func NewMigration(filePath string) (*Migration, error) {
+	return NewMigrationFS(nil, filePath)
+}
+
+// NewMigrationFS creates a new Migration instance from a file path in the fs.FS. if fsys is nil, will use the OS file system.
+//
+// For the UP/DOWN file pair, the filePath is the .up.sql file, the version is the name without suffix.
+func NewMigrationFS(fsys fs.FS, filePath string) (*Migration, error) {
+	return NewMigrationWith(fsys, filePath, nil)
+}
+
+// NewMigrationWith creates a new Migration instance like NewMigrationFS, the file name is parsed by the version scheme.
+//
+//   - scheme: if is nil, will use the DefaultScheme.
+func NewMigrationWith(fsys fs.FS, filePath string, scheme *VersionScheme) (*Migration, error) {
+	if strings.HasSuffix(filePath, UpFileSuffix) {
+		return newFilePairMigration(fsys, filePath)
+	}
+
 	// Extract timestamp from filename
-	fileName := filepath.Base(filePath)
-	fi, err := parseFilename(fileName)
+	fileName := path.Base(filepath.ToSlash(filePath))
+	if IsRepeatableFile(fileName) {
+		return &Migration{
+			FileName:    fileName,
+			FilePath:    filePath,
+			Version:     fileName,
+			Description: repeatableName(fileName),
+			Repeatable:  true,
+			fsys:        fsys,
+		}, nil
+	}
+
+	fi, err := scheme.ParseFilename(fileName)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Migration{
-		FileName:  fileName,
-		FilePath:  filePath,
-		Timestamp: fi.Time,
-		SortKey:   fi.Date,
-		Version:   fileName,
+		FileName:    fileName,
+		FilePath:    filePath,
+		Timestamp:   fi.Time,
+		SortKey:     fi.Date,
+		Version:     fileName,
+		Description: fi.Name,
+		fsys:        fsys,
 	}, nil
 }
 
+// newFilePairMigration creates the migration from the .up.sql file, the .down.sql file is optional.
+func newFilePairMigration(fsys fs.FS, upPath string) (*Migration, error) {
+	fileName := path.Base(filepath.ToSlash(upPath))
+	version, fi, err := parsePairFilename(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migration{
+		FileName:    fileName,
+		FilePath:    upPath,
+		Timestamp:   fi.Time,
+		SortKey:     fi.Date,
+		Version:     version,
+		Description: fi.Name,
+		fsys:        fsys,
+	}
+
+	downPath := strings.TrimSuffix(upPath, UpFileSuffix) + DownFileSuffix
+	if st, err1 := fs.Stat(orOSFS(fsys), downPath); err1 == nil && !st.IsDir() {
+		m.DownFilePath = downPath
+	}
+	return m, nil
+}
+
 // Parse reads migration file and parse it contents.
 func (m *Migration) Parse() error {
-	contents, err := os.ReadFile(m.FilePath)
+	// Go-code migration, nothing to parse
+	if m.IsGoCode() {
+		return nil
+	}
+
+	contents, err := fs.ReadFile(orOSFS(m.fsys), m.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file: %s", err)
 	}
 
 	m.Contents = string(contents)
+	m.Checksum = checksumOf(m.Contents)
+	m.Requires, m.requiresLoaded = parseRequires(m.Contents), true
+	if m.IsFilePair() {
+		return m.parseFilePair()
+	}
 	return m.ParseContents()
 }
 
-// ParseContents parses migration file contents, extracting UP and DOWN sections
+// parseFilePair parses the UP/DOWN file pair, the whole file is the section. Options can be set in the .up.sql file.
+func (m *Migration) parseFilePair() error {
+	m.Options = Options{}
+	for _, c := range m.lexer().LineComments(m.Contents) {
+		trimmed := strings.TrimSpace(c.Text)
+		if c.AtLineStart(m.Contents) && strings.HasPrefix(trimmed, MarkOption) {
+			if err := m.Options.ParseOptions(trimmed[len(MarkOption):]); err != nil {
+				return fmt.Errorf("migration file %s:%d: %v", m.FilePath, c.Line, err)
+			}
+		}
+	}
+
+	m.UpSection, m.upLine = trimSection(m.Contents, 1)
+	if m.UpSection == "" {
+		return fmt.Errorf("migration file %s does not contain valid SQL", m.FilePath)
+	}
+
+	m.DownSection, m.downLine = "", 0
+	if m.DownFilePath != "" {
+		contents, err := fs.ReadFile(orOSFS(m.fsys), m.DownFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file: %s", err)
+		}
+		m.DownSection, m.downLine = trimSection(string(contents), 1)
+		m.Checksum = checksumOf(m.Contents, string(contents))
+	}
+	return nil
+}
+
+// IsFilePair checks the migration is a separate UP/DOWN file pair. eg: 000001_create_users.up.sql
+func (m *Migration) IsFilePair() bool { return strings.HasSuffix(m.FileName, UpFileSuffix) }
+
+// ParseContents parses migration file contents, extracting UP and DOWN sections.
+//
+// The section marker can be limited to database drivers, eg: "-- Migrate:UP driver=postgres,mysql".
+// The section matched the Driver is used first, otherwise use the generic section without driver.
 func (m *Migration) ParseContents() error {
 	if m.Contents == "" {
 		return fmt.Errorf("migration file contents is empty. file: %s", m.FilePath)
 	}
 
-	// 使用按行解析处理
-	lines := strings.Split(m.Contents, "\n")
-	var upLines, downLines []string
-	// "" for none, "up" for up section, "down" for down section
-	currentSection := ""
+	contents := m.Contents
+	m.Options = Options{}
+	m.UpSection, m.DownSection = "", ""
+	m.upLine, m.downLine = 0, 0
+
+	// current section, nil for none
+	var current *fileSection
+	// matched generic and driver-specific sections. key: up, down, up:DRIVER, down:DRIVER
+	seen := make(map[string]bool, 4)
+	var sections []*fileSection
+	endSection := func(end int) {
+		current.text, current.line = trimSection(contents[current.start:end], current.line)
+	}
 
-	// TODO 后续支持在前几行设置选项。格式：-- Migrate-option:OPTION=VALUE,OPTION1=VALUE1,
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		// 跳过空行
-		if trimmed == "" {
+	// 使用词法解析，只识别顶层(不在字符串、块注释内)且位于行首的标记注释
+	for _, c := range m.lexer().LineComments(contents) {
+		if !c.AtLineStart(contents) {
 			continue
 		}
 
-		// TODO 后续支持 UP, DOWN 后面跟自定义设置: -- Migrate:UP(option=value,)
-		if strings.HasPrefix(trimmed, MarkUp) {
-			currentSection = "up"
+		var name, args string
+		trimmed := strings.TrimSpace(c.Text)
+		switch {
+		case strings.HasPrefix(trimmed, MarkUp):
+			name, args = "up", trimmed[len(MarkUp):]
+		case strings.HasPrefix(trimmed, MarkDown):
+			name, args = "down", trimmed[len(MarkDown):]
+		case strings.HasPrefix(trimmed, MarkOption):
+			// 在头部设置选项。格式：-- Migrate-option:OPTION=VALUE,OPTION1=VALUE1,
+			if current != nil {
+				return fmt.Errorf("migration file %s:%d: '%s' must be placed before the UP section", m.FilePath, c.Line, MarkOption)
+			}
+			if err := m.Options.ParseOptions(trimmed[len(MarkOption):]); err != nil {
+				return fmt.Errorf("migration file %s:%d: %v", m.FilePath, c.Line, err)
+			}
 			continue
-		} else if strings.HasPrefix(trimmed, MarkDown) {
-			currentSection = "down"
+		default:
 			continue
 		}
 
-		// 跳过不需要的注释行
-		if strings.HasPrefix(trimmed, "-- ") {
-			continue
+		drivers, err := parseSectionDrivers(args)
+		if err != nil {
+			return fmt.Errorf("migration file %s:%d: %v", m.FilePath, c.Line, err)
+		}
+
+		keys := []string{name}
+		if len(drivers) > 0 {
+			keys = keys[:0]
+			for _, driver := range drivers {
+				keys = append(keys, name+":"+driver)
+			}
+		}
+		for _, key := range keys {
+			if seen[key] {
+				return fmt.Errorf("migration file %s:%d: duplicate '%s' section", m.FilePath, c.Line, trimmed)
+			}
+			seen[key] = true
 		}
 
-		// 根据当前部分添加行内容
-		switch currentSection {
-		case "up":
-			upLines = append(upLines, line)
-		case "down":
-			downLines = append(downLines, line)
+		if current != nil {
+			endSection(c.Offset)
 		}
+		current = &fileSection{name: name, drivers: drivers, start: c.End, line: c.Line + 1}
+		sections = append(sections, current)
+	}
+	if current != nil {
+		endSection(len(contents))
 	}
 
-	// 设置解析结果
-	m.UpSection = strings.Join(upLines, "\n")
-	if len(downLines) > 0 {
-		m.DownSection = strings.Join(downLines, "\n")
+	// 选择匹配当前驱动的部分，否则使用通用部分
+	for _, sec := range sections {
+		if !sec.matchDriver(m.Driver) {
+			continue
+		}
+
+		if sec.name == "up" {
+			if m.UpSection == "" || len(sec.drivers) > 0 {
+				m.UpSection, m.upLine = sec.text, sec.line
+			}
+		} else if m.DownSection == "" || len(sec.drivers) > 0 {
+			m.DownSection, m.downLine = sec.text, sec.line
+		}
 	}
 
 	// 验证必须包含 UP 部分
 	if m.UpSection == "" {
+		for _, sec := range sections {
+			if sec.name == "up" && len(sec.drivers) > 0 {
+				return fmt.Errorf("migration file %s does not contain '-- Migrate:UP' section for driver %q", m.FilePath, m.Driver)
+			}
+		}
 		return fmt.Errorf("migration file %s does not contain valid SQL in '-- Migrate:UP' section", m.FilePath)
 	}
 	return nil
 }
 
+// fileSection is a UP or DOWN section in the migration file
+type fileSection struct {
+	// name: up, down
+	name string
+	// drivers of the section, empty is generic section
+	drivers []string
+	// start offset and line of the section contents
+	start, line int
+	text        string
+}
+
+// matchDriver checks the section can be used for the driver
+func (s *fileSection) matchDriver(driver string) bool {
+	if len(s.drivers) == 0 {
+		return true
+	}
+	for _, d := range s.drivers {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSectionDrivers parses the arguments of section marker. eg: "driver=postgres,sqlite --"
+func parseSectionDrivers(args string) ([]string, error) {
+	args = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(args), "--"))
+	if args == "" {
+		return nil, nil
+	}
+
+	key, val, ok := strings.Cut(args, "=")
+	if key = strings.TrimSpace(key); !ok || key != "driver" && key != "drivers" {
+		return nil, fmt.Errorf("invalid section marker arguments %q, allow: driver=NAME,...", args)
+	}
+
+	var drivers []string
+	for _, name := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == '|' }) {
+		if name = strings.TrimSpace(name); name != "" {
+			drivers = append(drivers, migutil.FmtDriverName(name))
+		}
+	}
+	if len(drivers) == 0 {
+		return nil, fmt.Errorf("empty driver name in section marker %q", args)
+	}
+	return drivers, nil
+}
+
+// fileLexer for find the top-level markers in migration file of unknown driver. it is the union of common dialects,
+// but the backslash is not an escape char as standard SQL. eg: LIKE '%\_%' ESCAPE '\'
+var fileLexer = &sqlsplit.Splitter{Name: "file", Backtick: true, HashComment: true, DollarQuote: true}
+
+// lexer returns the lexer for find the markers by the Driver dialect.
+// eg: the backslash escape is only for mysql.
+func (m *Migration) lexer() *sqlsplit.Splitter {
+	if lexer := sqlsplit.ForDriver(m.Driver); lexer != sqlsplit.Default {
+		return lexer
+	}
+	return fileLexer
+}
+
+// trimSection trims the section contents, returns empty if there is no SQL statement(eg: only comments).
+// The line is the start line number of the trimmed section in file.
+func trimSection(section string, line int) (string, int) {
+	trimmed := strings.TrimSpace(section)
+	if len(sqlsplit.Split(trimmed)) == 0 {
+		return "", 0
+	}
+
+	leading := section[:strings.Index(section, trimmed)]
+	return trimmed, line + strings.Count(leading, "\n")
+}
+
 // ResetContents 重置迁移文件内容字段
 func (m *Migration) ResetContents() {
 	m.Contents = ""
 	m.UpSection = ""
 	m.DownSection = ""
+	m.upLine, m.downLine = 0, 0
 }
 
-// IsBefore 判断当前迁移文件是否早于指定迁移文件
+// Statements splits the UP or DOWN section to SQL statements by the driver dialect,
+// the Line of each statement is the line number in file.
+//
+//   - driver: formatted driver name. see migcom.DriverMySQL
+func (m *Migration) Statements(driver, direction string) []sqlsplit.Statement {
+	section, line := m.section(direction)
+	return splitSection(driver, section, line)
+}
+
+// section returns the UP or DOWN section and its start line in file
+func (m *Migration) section(direction string) (string, int) {
+	if direction == StatusDown {
+		return m.DownSection, m.downLine
+	}
+	return m.UpSection, m.upLine
+}
+
+func splitSection(driver, section string, line int) []sqlsplit.Statement {
+	statements := sqlsplit.ForDriver(driver).Split(section)
+	if line > 0 {
+		for i := range statements {
+			statements[i].Line += line - 1
+		}
+	}
+	return statements
+}
+
+// IsGoCode 判断是否是通过 Go 代码注册的迁移
+func (m *Migration) IsGoCode() bool { return m.UpFunc != nil }
+
+// Kind returns the kind of the migration: KindSQL, KindGo, KindRepeatable
+func (m *Migration) Kind() string {
+	if m.IsGoCode() {
+		return KindGo
+	}
+	if m.Repeatable {
+		return KindRepeatable
+	}
+	return KindSQL
+}
+
+// HasDown 判断是否有回滚的 DOWN 部分
+func (m *Migration) HasDown() bool {
+	if m.IsGoCode() {
+		return m.DownFunc != nil
+	}
+	return m.DownSection != ""
+}
+
+// Source returns the source of the migration: file path or "go:VERSION"
+func (m *Migration) Source() string {
+	if m.IsGoCode() {
+		return "go:" + m.Version
+	}
+	if m.FilePath == "" {
+		return m.FileName
+	}
+	return m.FilePath
+}
+
+// sourceOf returns the source of the UP or DOWN section. the DOWN section of file pair is in the .down.sql file.
+func (m *Migration) sourceOf(direction string) string {
+	if direction == StatusDown && m.DownFilePath != "" {
+		return m.DownFilePath
+	}
+	return m.Source()
+}
+
+// IsRepeatableFile checks the filename is a repeatable migration. eg: R-users-view.sql, R__users_view.sql
+func IsRepeatableFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".sql") && !strings.HasSuffix(fileName, UpFileSuffix) &&
+		(strings.HasPrefix(fileName, RepeatablePrefix) || strings.HasPrefix(fileName, "R__"))
+}
+
+// repeatableName returns the name of the repeatable migration file. eg: R-users-view.sql => users-view
+func repeatableName(fileName string) string {
+	name := strings.TrimSuffix(fileName, ".sql")
+	if strings.HasPrefix(name, "R__") {
+		return name[3:]
+	}
+	return strings.TrimPrefix(name, RepeatablePrefix)
+}
+
+// checksumOf returns the sha256 hex string of the contents.
+//
+// Multiple parts(eg: UP and DOWN files) are hashed with a length prefix each, so moving text
+// between the parts changes the checksum. A single part is hashed as is.
+func checksumOf(contents ...string) string {
+	h := sha256.New()
+	for _, s := range contents {
+		if len(contents) > 1 {
+			_, _ = fmt.Fprintf(h, "%d:", len(s))
+		}
+		h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IsBefore 判断当前迁移文件是否早于指定迁移文件. 可重复执行的迁移排在所有版本迁移之后
 func (m *Migration) IsBefore(other *Migration) bool {
+	if m.Repeatable || other.Repeatable {
+		if m.Repeatable && other.Repeatable {
+			return m.FileName < other.FileName
+		}
+		return other.Repeatable
+	}
 	if m.SortKey != "" && other.SortKey != "" {
 		if m.SortKey == other.SortKey {
 			return m.FileName < other.FileName
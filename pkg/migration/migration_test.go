@@ -1,7 +1,9 @@
 package migration
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/gookit/goutil/fsutil"
 	"github.com/gookit/goutil/testutil/assert"
@@ -53,3 +55,235 @@ func TestMigration_ParseContents(t *testing.T) {
 	assert.Empty(t, m.Contents)
 	assert.Empty(t, m.UpSection)
 }
+
+func TestMigration_ParseOptions(t *testing.T) {
+	m := &Migration{FilePath: "20260105-102400-add-index.sql"}
+	m.Contents = `--
+-- Migrate-option: no-transaction, timeout=30s
+-- Migrate-option: isolation=serializable, drivers=pgsql|mysql, tags=slow|index
+
+-- Migrate:UP
+CREATE INDEX CONCURRENTLY idx_users_age ON users(age);
+`
+	assert.NoErr(t, m.ParseContents())
+	assert.True(t, m.Options.NoTransaction)
+	assert.Eq(t, 30*time.Second, m.Options.Timeout)
+	assert.Eq(t, sql.LevelSerializable, m.Options.Isolation)
+	assert.Eq(t, []string{"postgres", "mysql"}, m.Options.Drivers)
+	assert.Eq(t, []string{"slow", "index"}, m.Options.Tags)
+	assert.True(t, m.Options.AllowDriver("postgres"))
+	assert.False(t, m.Options.AllowDriver("sqlite"))
+	assert.True(t, m.Options.HasTag("slow"))
+	assert.Eq(t, sql.LevelSerializable, m.Options.TxOptions().Isolation)
+
+	// reset on parse again
+	m.Contents = "-- Migrate:UP\nSELECT 1;"
+	assert.NoErr(t, m.ParseContents())
+	assert.False(t, m.Options.NoTransaction)
+	assert.Nil(t, m.Options.TxOptions())
+	assert.True(t, m.Options.AllowDriver("sqlite"))
+	assert.False(t, m.Options.RenderVars(false))
+	assert.True(t, m.Options.RenderVars(true))
+
+	// vars option
+	m.Contents = "-- Migrate-option: vars\n-- Migrate:UP\nSELECT 1;"
+	assert.NoErr(t, m.ParseContents())
+	assert.True(t, m.Options.RenderVars(false))
+	m.Contents = "-- Migrate-option: vars=off\n-- Migrate:UP\nSELECT 1;"
+	assert.NoErr(t, m.ParseContents())
+	assert.False(t, m.Options.RenderVars(true))
+
+	tests := map[string]string{
+		"-- Migrate-option: unknown=1\n-- Migrate:UP\nSELECT 1;":      "unknown migration option",
+		"-- Migrate-option: timeout=abc\n-- Migrate:UP\nSELECT 1;":    "invalid timeout option",
+		"-- Migrate-option: isolation=abc\n-- Migrate:UP\nSELECT 1;":  "invalid isolation option",
+		"-- Migrate-option: vars=abc\n-- Migrate:UP\nSELECT 1;":       "invalid vars option",
+		"-- Migrate:UP\n-- Migrate-option: no-transaction\nSELECT 1;": "must be placed before the UP section",
+	}
+	for contents, errMsg := range tests {
+		m.Contents = contents
+		assert.ErrSubMsg(t, m.ParseContents(), errMsg)
+	}
+}
+
+func TestMigration_Statements(t *testing.T) {
+	m := &Migration{FilePath: "20260105-102400-add-users.sql"}
+	m.Contents = `-- Migrate:UP
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY
+);
+
+-- comment line
+INSERT INTO users VALUES (1); INSERT INTO users VALUES (2);
+
+-- Migrate:DOWN
+DROP TABLE users;
+`
+	assert.NoErr(t, m.ParseContents())
+
+	statements := m.Statements("sqlite", StatusUp)
+	assert.Len(t, statements, 3)
+	assert.Eq(t, 2, statements[0].Line)
+	assert.Eq(t, 7, statements[1].Line)
+	assert.Eq(t, 7, statements[2].Line)
+	assert.Eq(t, "INSERT INTO users VALUES (2)", statements[2].SQL)
+
+	statements = m.Statements("sqlite", StatusDown)
+	assert.Len(t, statements, 1)
+	assert.Eq(t, 10, statements[0].Line)
+}
+
+func TestMigration_ParseContents_preserve(t *testing.T) {
+	m := &Migration{FilePath: "20260105-102400-seed-notes.sql"}
+	m.Contents = `-- Migrate:UP
+-- seed data
+INSERT INTO notes(body) VALUES ('line1
+-- Migrate:DOWN not a marker
+-- keep this line
+line4');
+/*
+-- Migrate:DOWN in block comment
+*/
+CREATE FUNCTION f() RETURNS int AS $$
+-- Migrate:DOWN in dollar quote
+SELECT 1;
+$$ LANGUAGE sql;
+
+-- Migrate:DOWN
+DELETE FROM notes;
+`
+	assert.NoErr(t, m.ParseContents())
+	assert.StrContains(t, m.UpSection, "-- seed data\nINSERT INTO notes(body) VALUES ('line1\n-- Migrate:DOWN not a marker\n-- keep this line\nline4');")
+	assert.StrContains(t, m.UpSection, "-- Migrate:DOWN in block comment")
+	assert.Eq(t, "DELETE FROM notes;", m.DownSection)
+
+	statements := m.Statements("postgres", StatusUp)
+	assert.Len(t, statements, 2)
+	assert.Eq(t, 3, statements[0].Line)
+	assert.Eq(t, 10, statements[1].Line)
+	assert.Eq(t, 16, m.Statements("postgres", StatusDown)[0].Line)
+
+	m.Contents = "-- Migrate:UP\nSELECT 1;\n-- Migrate:UP\nSELECT 2;"
+	assert.ErrSubMsg(t, m.ParseContents(), ":3: duplicate '-- Migrate:UP' section")
+
+	m.Contents = "-- Migrate:UP\n-- only comments\n-- Migrate:DOWN\nSELECT 1;"
+	assert.ErrSubMsg(t, m.ParseContents(), "does not contain valid SQL")
+}
+
+func TestRenderVars(t *testing.T) {
+	vars := map[string]string{"schema": "app", "owner": "app_role"}
+
+	sqlText, err := RenderVars("CREATE TABLE ${schema}.users(id INT);\nALTER TABLE ${schema}.users OWNER TO {{ .Vars.owner }};", vars)
+	assert.NoErr(t, err)
+	assert.Eq(t, "CREATE TABLE app.users(id INT);\nALTER TABLE app.users OWNER TO app_role;", sqlText)
+
+	// no variables
+	sqlText, err = RenderVars("SELECT '$1', $1 FROM t", vars)
+	assert.NoErr(t, err)
+	assert.Eq(t, "SELECT '$1', $1 FROM t", sqlText)
+
+	// undefined variables
+	_, err = RenderVars("CREATE TABLE ${tenant}.users(id INT) TABLESPACE ${tablespace}", vars)
+	assert.ErrMsg(t, err, "undefined variable: tenant, tablespace")
+	_, err = RenderVars("ALTER TABLE users OWNER TO {{ .Vars.role }}", vars)
+	assert.ErrSubMsg(t, err, `map has no entry for key "role"`)
+
+	// the string literals and comments are kept, escape by backslash
+	sqlText, err = RenderVars(`-- refers to ${HOME}
+INSERT INTO m VALUES ('{{1,2},{3,4}}', '${not_a_var}', {{ quote .Vars.owner }}); /* {{ end }} */
+SELECT \${schema}, $body$ ${x} $body$;`, vars)
+	assert.NoErr(t, err)
+	assert.Eq(t, `-- refers to ${HOME}
+INSERT INTO m VALUES ('{{1,2},{3,4}}', '${not_a_var}', 'app_role'); /* {{ end }} */
+SELECT ${schema}, $body$ ${x} $body$;`, sqlText)
+
+	sqlText, err = RenderVars("SELECT {{ quote .Vars.name }}", map[string]string{"name": "it's"})
+	assert.NoErr(t, err)
+	assert.Eq(t, "SELECT 'it''s'", sqlText)
+}
+
+func TestMigration_RenderStatements(t *testing.T) {
+	m := &Migration{FilePath: "20260105-102400-add-users.sql"}
+	m.Contents = `-- Migrate:UP
+CREATE TABLE ${schema}.users (id INTEGER);
+
+-- Migrate:DOWN
+DROP TABLE ${schema}.users;
+`
+	assert.NoErr(t, m.ParseContents())
+
+	statements, err := m.RenderStatements("postgres", StatusDown, map[string]string{"schema": "app"})
+	assert.NoErr(t, err)
+	assert.Len(t, statements, 1)
+	assert.Eq(t, "DROP TABLE app.users", statements[0].SQL)
+	assert.Eq(t, 5, statements[0].Line)
+
+	_, err = m.RenderStatements("postgres", StatusUp, map[string]string{"owner": "app"})
+	assert.ErrMsg(t, err, "failed to render migration 20260105-102400-add-users.sql: undefined variable: schema")
+}
+
+func TestMigration_ParseContents_driver(t *testing.T) {
+	contents := `-- Migrate:UP
+CREATE TABLE users (id INTEGER PRIMARY KEY);
+
+-- Migrate:UP driver=postgres
+CREATE TABLE users (id BIGSERIAL PRIMARY KEY);
+
+-- Migrate:UP driver=sqlite3,mysql --
+CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT);
+
+-- Migrate:DOWN
+DROP TABLE users;
+`
+	tests := []struct {
+		driver, up string
+		upLine     int
+	}{
+		{"", "CREATE TABLE users (id INTEGER PRIMARY KEY);", 2},
+		{"mssql", "CREATE TABLE users (id INTEGER PRIMARY KEY);", 2},
+		{"postgres", "CREATE TABLE users (id BIGSERIAL PRIMARY KEY);", 5},
+		{"sqlite", "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT);", 8},
+		{"mysql", "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT);", 8},
+	}
+	for _, tt := range tests {
+		m := &Migration{FilePath: "20260105-102400-add-users.sql", Contents: contents, Driver: tt.driver}
+		assert.NoErr(t, m.ParseContents())
+		assert.Eq(t, tt.up, m.UpSection)
+		assert.Eq(t, "DROP TABLE users;", m.DownSection)
+		assert.Eq(t, tt.upLine, m.Statements(tt.driver, StatusUp)[0].Line)
+	}
+
+	// no section for the driver
+	m := &Migration{FilePath: "20260105-102400-add-users.sql", Driver: "sqlite"}
+	m.Contents = "-- Migrate:UP driver=postgres\nCREATE TABLE users (id BIGSERIAL);"
+	assert.ErrSubMsg(t, m.ParseContents(), `section for driver "sqlite"`)
+
+	// duplicate driver section
+	m.Contents = "-- Migrate:UP driver=sqlite\nSELECT 1;\n-- Migrate:UP driver=mysql|sqlite\nSELECT 2;"
+	assert.ErrSubMsg(t, m.ParseContents(), "20260105-102400-add-users.sql:3: duplicate")
+
+	// invalid marker arguments
+	m.Contents = "-- Migrate:UP drivr=sqlite\nSELECT 1;"
+	assert.ErrSubMsg(t, m.ParseContents(), "invalid section marker arguments")
+}
+
+func TestMigration_ParseContents_backslash(t *testing.T) {
+	contents := `-- Migrate:UP
+SELECT * FROM t WHERE name LIKE '%\_%' ESCAPE '\';
+-- Migrate:DOWN
+DROP TABLE t;
+`
+	for _, driver := range []string{"", "postgres", "sqlite", "mssql"} {
+		m := &Migration{FilePath: "20260105-102400-escape.sql", Driver: driver, Contents: contents}
+		assert.NoErr(t, m.ParseContents())
+		assert.Eq(t, `SELECT * FROM t WHERE name LIKE '%\_%' ESCAPE '\';`, m.UpSection, driver)
+		assert.Eq(t, "DROP TABLE t;", m.DownSection, driver)
+	}
+
+	// mysql: the backslash escapes the quote
+	m := &Migration{FilePath: "20260105-102400-escape.sql", Driver: "mysql"}
+	m.Contents = "-- Migrate:UP\nINSERT INTO t VALUES ('it\\'s');\n-- Migrate:DOWN\nDELETE FROM t;"
+	assert.NoErr(t, m.ParseContents())
+	assert.Eq(t, `INSERT INTO t VALUES ('it\'s');`, m.UpSection)
+	assert.Eq(t, "DELETE FROM t;", m.DownSection)
+}
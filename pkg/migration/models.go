@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -13,17 +14,44 @@ const (
 	StatusSkip = "skip"
 	// StatusPending represents a pending migration status
 	StatusPending = "pending"
+	// StatusDirty represents a half-applied migration status. some statements of
+	// a no-transaction migration executed, but others failed.
+	StatusDirty = "dirty"
+	// StatusOutdated represents an applied repeatable migration, but its file has been changed.
+	StatusOutdated = "outdated"
 )
 
 const (
 	MarkUp   = "-- Migrate:UP"
 	MarkDown = "-- Migrate:DOWN"
+	// UpFileSuffix, DownFileSuffix the suffix of the separate UP/DOWN migration file pair.
+	// eg: 000001_create_users.up.sql, 000001_create_users.down.sql
+	UpFileSuffix   = ".up.sql"
+	DownFileSuffix = ".down.sql"
+	// RepeatablePrefix the filename prefix of the repeatable migration. eg: R-users-view.sql
+	// The flyway style R__users_view.sql is also supported.
+	RepeatablePrefix = "R-"
+	// MarkOption the header line for set migration options. see Options
+	MarkOption = "-- Migrate-option:"
+	// MarkRequires the header line for declare the required migrations, multiple versions separated by comma.
+	// eg: -- requires: 20251105-102430-create-users.sql
+	MarkRequires = "-- requires:"
 	// DateLayout defines the layout for migration filename
 	DateLayout   = "20060102-150405"
 	DayLayout    = "20060102"
 	PrefixFormat = "YYYYMMDD-NNNNNN"
 )
 
+// kinds of the migration. see Migration.Kind
+const (
+	// KindSQL the versioned SQL file migration
+	KindSQL = "sql"
+	// KindGo the Go-code migration. see NewGoMigration
+	KindGo = "go"
+	// KindRepeatable the repeatable SQL file migration
+	KindRepeatable = "repeatable"
+)
+
 // StatusText returns the text representation of a migration status
 func StatusText(status string) string {
 	switch status {
@@ -35,11 +63,33 @@ func StatusText(status string) string {
 		return "skipped"
 	case StatusPending:
 		return "pending"
+	case StatusDirty:
+		return "dirty"
+	case StatusOutdated:
+		return "outdated"
 	default:
 		return "unknown"
 	}
 }
 
+// DirtyError is returned when a no-transaction migration is half-applied
+type DirtyError struct {
+	Version string
+	// Executed number of the executed statements
+	Executed int
+	// Total number of the statements
+	Total int
+	Err   error
+}
+
+// Error implements error
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("migration %s is half-applied(%d/%d statements executed): %v", e.Version, e.Executed, e.Total, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DirtyError) Unwrap() error { return e.Err }
+
 // Record represents a record in the database migrations table
 type Record struct {
 	// is migration filename
@@ -47,6 +97,20 @@ type Record struct {
 	AppliedAt time.Time `db:"applied_at"`
 	// up, skip, down.
 	Status string `db:"status"`
+	// Checksum of the migration file when applied. see Migration.Checksum
+	Checksum string `db:"checksum"`
+	// ExecutionMs the execution duration in milliseconds
+	ExecutionMs int64 `db:"execution_ms"`
+	// AppliedBy the OS user and host that applied the migration. eg: inhere@my-host
+	AppliedBy string `db:"applied_by"`
+	// MigliteVersion the version of miglite that applied the migration
+	MigliteVersion string `db:"miglite_version"`
+	// Description of the migration. see Migration.Description
+	Description string `db:"description"`
+	// Kind of the migration: sql, go, repeatable. see Migration.Kind
+	//
+	// NOTE: it is empty for the records applied by old version, they are all SQL file migrations.
+	Kind string `db:"kind"`
 }
 
 // NewRecord creates a new migration record
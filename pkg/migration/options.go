@@ -0,0 +1,153 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/miglite/internal/migutil"
+)
+
+// Options for a migration. set by the header lines of migration file:
+//
+//	-- Migrate-option: no-transaction, timeout=30s
+//	-- Migrate-option: isolation=serializable, drivers=postgres|mysql, tags=slow|data
+//
+// Multiple values of an option are separated by '|'.
+type Options struct {
+	// NoTransaction run the migration outside a transaction.
+	NoTransaction bool
+	// Timeout for execute the migration. 0 will use the Executor timeout.
+	Timeout time.Duration
+	// Isolation level of the transaction. 0 is the driver default.
+	Isolation sql.IsolationLevel
+	// Drivers allowed database drivers to run the migration. empty is all drivers.
+	Drivers []string
+	// Tags custom tags of the migration
+	Tags []string
+	// Vars render the template variables in the SQL. see VarsAuto, VarsOn, VarsOff
+	Vars string
+}
+
+// values of the vars option
+const (
+	// VarsAuto render the SQL only when any variables are set. it is the default
+	VarsAuto = ""
+	// VarsOn always render the SQL, an undefined variable is an error even if there are no variables
+	VarsOn = "on"
+	// VarsOff never render the SQL
+	VarsOff = "off"
+)
+
+// isolation level names. eg: read-committed
+var isolationLevels = map[string]sql.IsolationLevel{}
+
+func init() {
+	for lv := sql.LevelDefault; lv <= sql.LevelLinearizable; lv++ {
+		isolationLevels[strings.ReplaceAll(strings.ToLower(lv.String()), " ", "-")] = lv
+	}
+}
+
+// ParseOptions parses the option string. format: OPTION=VALUE,OPTION1=VALUE1,...
+func (o *Options) ParseOptions(str string) error {
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, val, _ := strings.Cut(item, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "no-transaction", "notx":
+			if val == "" {
+				o.NoTransaction = true
+			} else {
+				o.NoTransaction = val == "true" || val == "1" || val == "on"
+			}
+		case "timeout":
+			dur, err := time.ParseDuration(val)
+			if err != nil || dur < 0 {
+				return fmt.Errorf("invalid timeout option value %q", val)
+			}
+			o.Timeout = dur
+		case "isolation":
+			lv, ok := isolationLevels[strings.ToLower(val)]
+			if !ok {
+				return fmt.Errorf("invalid isolation option value %q", val)
+			}
+			o.Isolation = lv
+		case "drivers", "driver":
+			for _, driver := range splitOptionValues(val) {
+				o.Drivers = append(o.Drivers, migutil.FmtDriverName(driver))
+			}
+		case "tags", "tag":
+			o.Tags = append(o.Tags, splitOptionValues(val)...)
+		case "vars":
+			switch strings.ToLower(val) {
+			case "", "on", "true", "1":
+				o.Vars = VarsOn
+			case "off", "false", "0":
+				o.Vars = VarsOff
+			default:
+				return fmt.Errorf("invalid vars option value %q", val)
+			}
+		default:
+			return fmt.Errorf("unknown migration option %q", key)
+		}
+	}
+	return nil
+}
+
+// TxOptions returns the options for begin the transaction
+func (o *Options) TxOptions() *sql.TxOptions {
+	if o.Isolation == sql.LevelDefault {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: o.Isolation}
+}
+
+// AllowDriver checks the migration can run on the database driver
+func (o *Options) AllowDriver(driver string) bool {
+	if len(o.Drivers) == 0 {
+		return true
+	}
+
+	for _, d := range o.Drivers {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderVars checks the SQL should be rendered, hasVars is there are any variables set. see Vars
+func (o *Options) RenderVars(hasVars bool) bool {
+	if o.Vars == VarsAuto {
+		return hasVars
+	}
+	return o.Vars == VarsOn
+}
+
+// HasTag checks the migration has the tag
+func (o *Options) HasTag(tag string) bool {
+	for _, t := range o.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func splitOptionValues(val string) []string {
+	var ss []string
+	for _, s := range strings.Split(val, "|") {
+		if s = strings.TrimSpace(s); s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
@@ -1,10 +1,10 @@
 package migration
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/gookit/goutil/x/stdio"
 	"github.com/gookit/miglite/internal/database"
@@ -14,6 +14,11 @@ import (
 //   - status=up: insert a new record
 //   - status=down: update the record
 func SaveRecord(db *database.DB, version, status string, tx *sql.Tx) error {
+	return SaveRecordContext(context.Background(), db, version, status, tx)
+}
+
+// SaveRecordContext records a migration in the database with context. see SaveRecord
+func SaveRecordContext(ctx context.Context, db *database.DB, version, status string, tx *sql.Tx) error {
 	provide, err := db.SqlProvider()
 	if err != nil {
 		return err
@@ -21,25 +26,25 @@ func SaveRecord(db *database.DB, version, status string, tx *sql.Tx) error {
 
 	// Check if the record already exists
 	var exists bool
-	err = db.QueryRow(provide.QueryExists(), version).Scan(&exists)
+	err = db.QueryRowContext(ctx, provide.QueryExists(db.QuotedTableName()), version).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check if migration exists: %v", err)
 	}
 
 	// Insert a new record
-	var aSql = provide.InsertMigration()
+	var aSql = provide.InsertMigration(db.QuotedTableName())
 	var args = []any{version, status}
 
 	// Update the existing record. eg: up -> down
 	if exists {
-		aSql = provide.UpdateMigration()
+		aSql = provide.UpdateMigration(db.QuotedTableName())
 		args = []any{status, version} // parameter order must be same as query
 	}
 
 	if tx == nil {
-		_, err = db.Exec(aSql, args...)
+		_, err = db.ExecContext(ctx, aSql, args...)
 	} else {
-		_, err = tx.Exec(aSql, args...)
+		_, err = tx.ExecContext(ctx, aSql, args...)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to record migration: %v", err)
@@ -47,54 +52,126 @@ func SaveRecord(db *database.DB, version, status string, tx *sql.Tx) error {
 	return nil
 }
 
+// SaveRecordInfoContext saves the details of the applied migration record. eg: checksum, execution_ms, applied_by
+func SaveRecordInfoContext(ctx context.Context, db *database.DB, record *Record, tx *sql.Tx) error {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return err
+	}
+
+	aSql := provide.UpdateRecordInfo(db.QuotedTableName())
+	args := []any{record.Checksum, record.ExecutionMs, record.AppliedBy, record.MigliteVersion, record.Description, record.Kind, record.Version}
+	if tx == nil {
+		_, err = db.ExecContext(ctx, aSql, args...)
+	} else {
+		_, err = tx.ExecContext(ctx, aSql, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration info: %v", err)
+	}
+	return nil
+}
+
 // GetMigrationsStatus retrieves the status of all migrations
 func GetMigrationsStatus(db *database.DB, allMigrations []*Migration) ([]Record, error) {
+	records, err := GetRecords(db)
+	if err != nil {
+		return nil, err
+	}
+	return StatusOf(records, allMigrations), nil
+}
+
+// GetRecords retrieves all records in the migrations table
+func GetRecords(db *database.DB) ([]Record, error) {
 	provide, err := db.SqlProvider()
 	if err != nil {
 		return nil, err
 	}
 
 	// Query the database for applied migrations
-	rows, err := db.Query(provide.QueryAll())
+	rows, err := db.Query(provide.QueryAll(db.QuotedTableName()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query migration status: %v", err)
 	}
 	defer stdio.SafeClose(rows)
 
-	// Create status list for all migrations
-	var statuses []Record
-
-	// Create a map of applied migrations
-	appliedMigrations := make(map[string]Record)
+	var records []Record
 	for rows.Next() {
-		var appliedAt time.Time
-		var version, status string
-		if err := rows.Scan(&version, &status, &appliedAt); err != nil {
+		record, err := scanRecord(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan migration status: %v", err)
 		}
-		appliedMigrations[version] = Record{
-			Version:   version,
-			Status:    status,
-			AppliedAt: appliedAt,
-		}
+		records = append(records, *record)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating migration status rows: %v", err)
 	}
+	return records, nil
+}
+
+// StatusOf returns the status of all migrations by the records.
+// The Checksum of repeatable migrations should be set by Parse, for check it is outdated.
+func StatusOf(records []Record, allMigrations []*Migration) []Record {
+	// Create a map of applied migrations
+	appliedMigrations := make(map[string]Record, len(records))
+	for _, record := range records {
+		appliedMigrations[record.Version] = record
+	}
 
+	// Create status list for all migrations
+	statuses := make([]Record, 0, len(allMigrations))
 	for _, migration := range allMigrations {
 		if status, exists := appliedMigrations[migration.Version]; exists {
+			// the repeatable migration file has been changed after applied
+			if migration.Repeatable && status.Status == StatusUp && status.Checksum != migration.Checksum {
+				status.Status = StatusOutdated
+			}
 			statuses = append(statuses, status)
 		} else {
 			statuses = append(statuses, Record{
-				Version: migration.FileName,
+				Version: migration.Version,
 				Status:  StatusPending,
 			})
 		}
 	}
+	return statuses
+}
+
+// GetRecord retrieves the record of the migration version, returns nil if not exists.
+func GetRecord(db *database.DB, version string) (*Record, error) {
+	provide, err := db.SqlProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := scanRecord(db.QueryRow(provide.QueryOne(db.QuotedTableName()), version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query migration record: %v", err)
+	}
+	return record, nil
+}
+
+// scanRecord scans a record by the database.RecordColumns, the columns added by upgrade are nullable.
+func scanRecord(row interface{ Scan(dest ...any) error }) (*Record, error) {
+	var record Record
+	var checksum, appliedBy, migliteVersion, description, kind sql.NullString
+	var executionMs sql.NullInt64
+	err := row.Scan(&record.Version, &record.Status, &record.AppliedAt, &checksum, &executionMs, &appliedBy, &migliteVersion, &description, &kind)
+	if err != nil {
+		return nil, err
+	}
 
-	return statuses, nil
+	record.Checksum = checksum.String
+	record.ExecutionMs = executionMs.Int64
+	record.AppliedBy = appliedBy.String
+	record.MigliteVersion = migliteVersion.String
+	record.Description = description.String
+	record.Kind = kind.String
+	return &record, nil
 }
 
 // IsApplied checks if a specific migration has been applied(status=up)
@@ -105,7 +182,7 @@ func IsApplied(db *database.DB, version string) (bool, string, error) {
 	}
 
 	var status string
-	err = db.QueryRow(provide.QueryStatus(), version).Scan(&status)
+	err = db.QueryRow(provide.QueryStatus(db.QuotedTableName()), version).Scan(&status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, "", nil
@@ -122,7 +199,7 @@ func GetAppliedSortedByVersion(db *database.DB, limit int) ([]Record, error) {
 		return nil, err
 	}
 
-	rows, err := db.Query(provide.GetAppliedSortedByVersion(), StatusUp, limit)
+	rows, err := db.Query(provide.GetAppliedSortedByVersion(db.QuotedTableName()), StatusUp, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query applied migrations: %v", err)
 	}
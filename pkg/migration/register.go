@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// MigrateFunc is the function for run a Go-code migration in the transaction
+type MigrateFunc func(ctx context.Context, tx *sql.Tx) error
+
+// MigrationFn is the function for update options of a Migration
+type MigrationFn func(m *Migration)
+
+// global registry for Go-code migrations
+var (
+	regMu      sync.Mutex
+	registered []*Migration
+)
+
+// NewGoMigration creates a new Go-code migration
+//
+//   - version: format is YYYYMMDD-NNNNNN-{name}, eg: 20260105-102400-fill-user-age
+//   - up: required, the function to apply the migration
+//   - down: optional, the function to rollback the migration
+func NewGoMigration(version string, up, down MigrateFunc, fns ...MigrationFn) (*Migration, error) {
+	if up == nil {
+		return nil, fmt.Errorf("the UP function is required for migration: %s", version)
+	}
+
+	fi, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	mig := &Migration{
+		FileName:    version,
+		Timestamp:   fi.Time,
+		SortKey:     fi.Date,
+		Version:     version,
+		Description: fi.Name,
+		UpFunc:      up,
+		DownFunc:    down,
+	}
+	for _, fn := range fns {
+		fn(mig)
+	}
+	return mig, nil
+}
+
+// Register adds a Go-code migration to the global registry. Usually call it on init().
+//
+// NOTE: will panic on invalid or duplicate version.
+//
+// Example:
+//
+//	func init() {
+//		migration.Register("20260105-102400-fill-user-age", upFn, downFn)
+//	}
+func Register(version string, up, down MigrateFunc, fns ...MigrationFn) {
+	mig, err := NewGoMigration(version, up, down, fns...)
+	if err != nil {
+		panic(err)
+	}
+
+	regMu.Lock()
+	defer regMu.Unlock()
+	for _, m := range registered {
+		if m.Version == version {
+			panic(fmt.Sprintf("duplicate migration version registered: %s", version))
+		}
+	}
+	registered = append(registered, mig)
+}
+
+// Registered returns all globally registered Go-code migrations
+func Registered() []*Migration {
+	regMu.Lock()
+	defer regMu.Unlock()
+	return append([]*Migration(nil), registered...)
+}
+
+// Merge merges multiple migration lists and sorts them by version prefix and the requires. see SortByRequires
+// An error is returned when the same version appears more than once.
+func Merge(lists ...[]*Migration) ([]*Migration, error) {
+	var migrations []*Migration
+	versions := make(map[string]*Migration)
+
+	for _, list := range lists {
+		for _, mig := range list {
+			if exist, ok := versions[mig.Version]; ok {
+				return nil, fmt.Errorf("duplicate migration version %q (%s and %s)", mig.Version, exist.Source(), mig.Source())
+			}
+			versions[mig.Version] = mig
+			migrations = append(migrations, mig)
+		}
+	}
+
+	return SortByRequires(migrations)
+}
@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func noopMigrate(_ context.Context, _ *sql.Tx) error { return nil }
+
+func TestNewGoMigration(t *testing.T) {
+	mig, err := NewGoMigration("20260105-102400-fill-user-age", noopMigrate, nil)
+	assert.NoErr(t, err)
+	assert.True(t, mig.IsGoCode())
+	assert.False(t, mig.HasDown())
+	assert.Eq(t, "20260105-102400", mig.SortKey)
+	assert.Eq(t, "go:20260105-102400-fill-user-age", mig.Source())
+	assert.NoErr(t, mig.Parse())
+
+	// invalid version
+	_, err = NewGoMigration("fill-user-age", noopMigrate, nil)
+	assert.Err(t, err)
+	// missing the separator between the time and name
+	_, err = NewGoMigration("20260105-102400abc", noopMigrate, nil)
+	assert.ErrSubMsg(t, err, "invalid migration version")
+	mig, err = NewGoMigration("20260105-102400_fill_user_age", noopMigrate, nil)
+	assert.NoErr(t, err)
+	assert.Eq(t, "fill_user_age", mig.Description)
+	// missing up func
+	_, err = NewGoMigration("20260105-102400-fill-user-age", nil, nil)
+	assert.Err(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	m1, err := NewMigration("testdata/20260504-100070-create-vp-audit-log.sql")
+	assert.NoErr(t, err)
+	m2, err := NewGoMigration("20260504-100071-fill-vp-audit-log", noopMigrate, noopMigrate)
+	assert.NoErr(t, err)
+	m3, err := NewMigration("testdata/20260504-100072-add-vp-audit-index.sql")
+	assert.NoErr(t, err)
+
+	migs, err := Merge([]*Migration{m3, m1}, []*Migration{m2})
+	assert.NoErr(t, err)
+	assert.Len(t, migs, 3)
+	assert.Eq(t, m1.Version, migs[0].Version)
+	assert.Eq(t, m2.Version, migs[1].Version)
+	assert.Eq(t, m3.Version, migs[2].Version)
+
+	// duplicate version
+	_, err = Merge([]*Migration{m1, m2}, []*Migration{m2})
+	assert.Err(t, err)
+	assert.StrContains(t, err.Error(), "duplicate migration version")
+}
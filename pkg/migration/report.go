@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"time"
+)
+
+// result status for run a migration
+const (
+	// ResultApplied the UP migration executed successfully
+	ResultApplied = "applied"
+	// ResultRolled the DOWN migration executed successfully
+	ResultRolled = "rolled"
+	// ResultSkipped the migration is marked as skipped
+	ResultSkipped = "skipped"
+	// ResultIgnored the migration is not executed. eg: already applied, empty DOWN section
+	ResultIgnored = "ignored"
+	// ResultCanceled the migration is canceled by user
+	ResultCanceled = "canceled"
+	// ResultFailed the migration executed failed
+	ResultFailed = "failed"
+	// ResultDryRun the migration is not executed on dry-run, see Result.SQL
+	ResultDryRun = "dry-run"
+)
+
+// Result represents the result of run a migration
+type Result struct {
+	Version string
+	// Action of run: up, down, skip
+	Action string
+	// Status of the result. see ResultApplied, ResultFailed etc.
+	Status string
+	// Message extra message for the result. eg: reason of ignored
+	Message string
+	// StartedAt start time of run the migration
+	StartedAt time.Time
+	Duration  time.Duration
+	// Statements number of executed SQL statements
+	Statements int
+	// RowsAffected total rows affected by the executed SQL statements, if available
+	RowsAffected int64
+	// SQL the rendered SQL statements on dry-run
+	SQL []string
+	// Err the error on run failed
+	Err error
+}
+
+// NewResult creates a new Result for the migration
+func NewResult(mig *Migration, action string) *Result {
+	return &Result{
+		Version:   mig.Version,
+		Action:    action,
+		StartedAt: time.Now(),
+	}
+}
+
+// Done sets the status and duration of the result
+func (r *Result) Done(status string) *Result {
+	r.Status = status
+	r.Duration = time.Since(r.StartedAt)
+	return r
+}
+
+// Fail sets the result status to failed with error
+func (r *Result) Fail(err error) *Result {
+	r.Err = err
+	return r.Done(ResultFailed)
+}
+
+// IsFailed checks if the result is failed
+func (r *Result) IsFailed() bool { return r.Status == ResultFailed }
+
+// RunReport represents the report of run migrations
+type RunReport struct {
+	// Action of run: up, down, skip
+	Action    string
+	StartedAt time.Time
+	Duration  time.Duration
+	// Total number of migrations to process
+	Total int
+	// DryRun the migrations are not executed, only render the SQL. see ResultDryRun
+	DryRun bool
+	// Results of each processed migration
+	Results []*Result
+}
+
+// NewRunReport creates a new RunReport
+func NewRunReport(action string, total int) *RunReport {
+	return &RunReport{
+		Action:    action,
+		Total:     total,
+		StartedAt: time.Now(),
+	}
+}
+
+// Add a result to the report
+func (r *RunReport) Add(res *Result) { r.Results = append(r.Results, res) }
+
+// Finish sets the duration of the report
+func (r *RunReport) Finish() *RunReport {
+	r.Duration = time.Since(r.StartedAt)
+	return r
+}
+
+// Count returns the number of results with the status
+func (r *RunReport) Count(status string) int {
+	var n int
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns the error of the first failed result
+func (r *RunReport) Err() error {
+	for _, res := range r.Results {
+		if res.Err != nil {
+			return res.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRunReport(t *testing.T) {
+	m1, err := NewGoMigration("20260105-102400-fill-user-age", noopMigrate, nil)
+	assert.NoErr(t, err)
+	m2, err := NewGoMigration("20260105-102500-fill-user-name", noopMigrate, nil)
+	assert.NoErr(t, err)
+
+	report := NewRunReport(StatusUp, 2)
+	report.Add(NewResult(m1, StatusUp).Done(ResultApplied))
+	assert.NoErr(t, report.Err())
+
+	res := NewResult(m2, StatusUp).Fail(errors.New("exec error"))
+	report.Add(res)
+	assert.True(t, res.IsFailed())
+	assert.Eq(t, m2.Version, res.Version)
+
+	report.Finish()
+	assert.Eq(t, 1, report.Count(ResultApplied))
+	assert.Eq(t, 1, report.Count(ResultFailed))
+	assert.ErrMsg(t, report.Err(), "exec error")
+	assert.True(t, report.Duration > 0)
+}
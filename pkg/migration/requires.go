@@ -0,0 +1,159 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LoadRequires loads the Requires from the header lines of the migration file, the file contents is not kept.
+func (m *Migration) LoadRequires() error {
+	if m.requiresLoaded || m.IsGoCode() || m.FilePath == "" {
+		return nil
+	}
+
+	contents := m.Contents
+	if contents == "" {
+		bs, err := fs.ReadFile(orOSFS(m.fsys), m.FilePath)
+		if err != nil {
+			// the not exists file will be reported on Parse
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to read migration file: %s", err)
+		}
+		contents = string(bs)
+	}
+
+	m.Requires, m.requiresLoaded = parseRequires(contents), true
+	return nil
+}
+
+// parseRequires parses the required versions from the header lines before the UP section.
+//
+// eg: "-- requires: 20251105-102430-create-users.sql, 20251105-102431-create-roles"
+func parseRequires(contents string) []string {
+	var requires []string
+	for _, c := range fileLexer.LineComments(contents) {
+		if !c.AtLineStart(contents) {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(c.Text)
+		if strings.HasPrefix(trimmed, MarkUp) || strings.HasPrefix(trimmed, MarkDown) {
+			break
+		}
+		if strings.HasPrefix(trimmed, MarkRequires) {
+			requires = append(requires, strings.FieldsFunc(trimmed[len(MarkRequires):], func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})...)
+		}
+	}
+	return requires
+}
+
+// SortByRequires sorts the migrations in the topological order of the Requires,
+// the migrations without dependency between them are ordered by IsBefore(timestamp).
+//
+// An error is returned when a required migration does not exist or the Requires has a cycle.
+func SortByRequires(migrations []*Migration) ([]*Migration, error) {
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].IsBefore(migrations[j])
+	})
+
+	versions := make(map[string]*Migration, len(migrations))
+	for _, mig := range migrations {
+		versions[mig.Version] = mig
+	}
+
+	// the required migrations of each migration
+	deps := make(map[*Migration][]*Migration)
+	for _, mig := range migrations {
+		if err := mig.LoadRequires(); err != nil {
+			return nil, err
+		}
+
+		for _, version := range mig.Requires {
+			// allow omit the .sql suffix. eg: 20251105-102430-create-users
+			req, ok := versions[version]
+			if !ok {
+				req, ok = versions[version+".sql"]
+			}
+			if !ok {
+				return nil, fmt.Errorf("migration %s requires %q, but it does not exist", mig.Version, version)
+			}
+			deps[mig] = append(deps[mig], req)
+		}
+	}
+	if len(deps) == 0 {
+		return migrations, nil
+	}
+
+	// always pick the earliest migration that all its required migrations are sorted
+	sorted := make([]*Migration, 0, len(migrations))
+	done := make(map[*Migration]bool, len(migrations))
+	for len(sorted) < len(migrations) {
+		var next *Migration
+		for _, mig := range migrations {
+			if !done[mig] && allDone(deps[mig], done) {
+				next = mig
+				break
+			}
+		}
+		if next == nil {
+			return nil, requiresCycleError(migrations, deps, done)
+		}
+
+		done[next] = true
+		sorted = append(sorted, next)
+	}
+	return sorted, nil
+}
+
+func allDone(migs []*Migration, done map[*Migration]bool) bool {
+	for _, mig := range migs {
+		if !done[mig] {
+			return false
+		}
+	}
+	return true
+}
+
+// requiresCycleError finds a cycle in the remaining migrations, each of them has an unsorted required migration.
+func requiresCycleError(migrations []*Migration, deps map[*Migration][]*Migration, done map[*Migration]bool) error {
+	var path []*Migration
+	index := make(map[*Migration]int)
+
+	var cur *Migration
+	for _, mig := range migrations {
+		if !done[mig] {
+			cur = mig
+			break
+		}
+	}
+
+	for {
+		if i, ok := index[cur]; ok {
+			path = append(path[i:], cur)
+			break
+		}
+		index[cur] = len(path)
+		path = append(path, cur)
+
+		for _, req := range deps[cur] {
+			if !done[req] {
+				cur = req
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(path))
+	for i, mig := range path {
+		names[i] = mig.Version
+	}
+	return fmt.Errorf("migrations have circular requires: %s", strings.Join(names, " -> "))
+}
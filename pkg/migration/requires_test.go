@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestParseRequires(t *testing.T) {
+	contents := `-- requires: 20251105-102430-create-users.sql, 20251105-102431-create-roles
+-- Migrate-option:notx=true
+-- requires:	20251105-102432-create-posts
+-- Migrate:UP
+-- requires: 20251105-102433-in-section
+CREATE TABLE user_roles(id INTEGER PRIMARY KEY);
+`
+	assert.Eq(t, []string{
+		"20251105-102430-create-users.sql",
+		"20251105-102431-create-roles",
+		"20251105-102432-create-posts",
+	}, parseRequires(contents))
+	assert.Empty(t, parseRequires("-- Migrate:UP\nSELECT 1;"))
+}
+
+func TestSortByRequires(t *testing.T) {
+	newMig := func(version string, requires ...string) *Migration {
+		mig, err := NewGoMigration(version, noopMigrate, nil, func(m *Migration) {
+			m.Requires = requires
+		})
+		assert.NoErr(t, err)
+		return mig
+	}
+
+	// c requires the later migration d, the others keep order by timestamp
+	a := newMig("20251105-102430-a")
+	b := newMig("20251105-102431-b")
+	c := newMig("20251105-102432-c", "20251105-102434-d")
+	d := newMig("20251105-102434-d", "20251105-102430-a")
+	e := newMig("20251105-102433-e")
+
+	migs, err := SortByRequires([]*Migration{e, d, c, b, a})
+	assert.NoErr(t, err)
+	var names []string
+	for _, mig := range migs {
+		names = append(names, mig.Description)
+	}
+	assert.Eq(t, []string{"a", "b", "e", "d", "c"}, names)
+
+	// omit the .sql suffix
+	f1 := &Migration{FileName: "20251105-102435-f1.sql", Version: "20251105-102435-f1.sql", SortKey: "20251105-102435", requiresLoaded: true}
+	f2 := &Migration{FileName: "20251105-102430-f2.sql", Version: "20251105-102430-f2.sql", SortKey: "20251105-102430", requiresLoaded: true}
+	f2.Requires = []string{"20251105-102435-f1"}
+	migs, err = SortByRequires([]*Migration{f2, f1})
+	assert.NoErr(t, err)
+	assert.Eq(t, f1, migs[0])
+	assert.Eq(t, f2, migs[1])
+
+	// missing required migration
+	_, err = SortByRequires([]*Migration{a, newMig("20251105-102439-x", "20251105-102438-not-exists")})
+	assert.ErrSubMsg(t, err, `migration 20251105-102439-x requires "20251105-102438-not-exists", but it does not exist`)
+
+	// cycle
+	x := newMig("20251105-102440-x", "20251105-102442-z")
+	y := newMig("20251105-102441-y", "20251105-102440-x")
+	z := newMig("20251105-102442-z", "20251105-102441-y")
+	_, err = SortByRequires([]*Migration{a, x, y, z})
+	assert.ErrSubMsg(t, err, "circular requires: 20251105-102440-x -> 20251105-102442-z -> 20251105-102441-y -> 20251105-102440-x")
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// DriftModified the migration file has been changed after applied
+	DriftModified = "modified"
+	// DriftMissing the migration file has been deleted after applied
+	DriftMissing = "missing"
+)
+
+// policies for the out-of-order pending migrations. see FindOutOfOrder
+const (
+	// OutOfOrderAllow run the out-of-order migrations silently
+	OutOfOrderAllow = "allow"
+	// OutOfOrderWarn run the out-of-order migrations with warning
+	OutOfOrderWarn = "warn"
+	// OutOfOrderError refuse to run the out-of-order migrations
+	OutOfOrderError = "error"
+)
+
+// ParseOutOfOrderPolicy parses the out-of-order policy setting, default is OutOfOrderWarn.
+func ParseOutOfOrderPolicy(str string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(str)); policy {
+	case "":
+		return OutOfOrderWarn, nil
+	case OutOfOrderAllow, OutOfOrderWarn, OutOfOrderError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid out-of-order policy %q, allow: allow, warn, error", str)
+	}
+}
+
+// Drift is an applied migration whose file has been changed or deleted
+type Drift struct {
+	Version string
+	// Reason of the drift: modified, missing
+	Reason string
+	// Checksum saved when applied, Current is the checksum of current file
+	Checksum, Current string
+}
+
+// FindDrifts finds the applied migrations whose file has been changed or deleted.
+//
+// The Checksum of migrations should be set by Parse. Skip the records without checksum(applied by old version),
+// the Go-code migrations and repeatable migrations are not checked the checksum.
+// The applied Go-code migrations are not reported as missing, they are not registered when run by the CLI.
+func FindDrifts(records []Record, migrations []*Migration) []Drift {
+	migMap := make(map[string]*Migration, len(migrations))
+	for _, mig := range migrations {
+		migMap[mig.Version] = mig
+	}
+
+	var drifts []Drift
+	for _, record := range records {
+		if record.Status != StatusUp {
+			continue
+		}
+
+		mig, ok := migMap[record.Version]
+		if !ok {
+			// the Go-code migrations maybe not registered. eg: run by the CLI
+			if record.Kind == KindGo {
+				continue
+			}
+			drifts = append(drifts, Drift{Version: record.Version, Reason: DriftMissing, Checksum: record.Checksum})
+			continue
+		}
+		if mig.Repeatable || record.Checksum == "" || mig.Checksum == "" {
+			continue
+		}
+		if mig.Checksum != record.Checksum {
+			drifts = append(drifts, Drift{Version: record.Version, Reason: DriftModified, Checksum: record.Checksum, Current: mig.Checksum})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Version < drifts[j].Version })
+	return drifts
+}
+
+// FindOutOfOrder finds the pending migrations older than the newest applied migration,
+// eg: a branch merges an older-timestamped file after newer ones are already applied.
+//
+// The migrations should be sorted by SortByRequires, "older" means before in the run order.
+// The repeatable migrations are always run after the versioned migrations, so they are not checked.
+//
+//   - driver: the current database driver. The migrations that its Options exclude the driver are never applied,
+//     so they are not checked, the Options should be set by Parse.
+func FindOutOfOrder(records []Record, migrations []*Migration, driver string) []*Migration {
+	statuses := make(map[string]string, len(records))
+	for _, record := range records {
+		statuses[record.Version] = record.Status
+	}
+
+	// find the newest applied(or skipped) migration in run order
+	newest := -1
+	for i, mig := range migrations {
+		if st := statuses[mig.Version]; !mig.Repeatable && (st == StatusUp || st == StatusSkip) {
+			newest = i
+		}
+	}
+
+	// pending: not applied or rolled back
+	var migs []*Migration
+	for _, mig := range migrations[:newest+1] {
+		if st := statuses[mig.Version]; !mig.Repeatable && (st == "" || st == StatusDown) && mig.Options.AllowDriver(driver) {
+			migs = append(migs, mig)
+		}
+	}
+	return migs
+}
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestFindDrifts(t *testing.T) {
+	migrations := []*Migration{
+		{Version: "20251105-102430-create-users.sql", Checksum: "aaa"},
+		{Version: "20251105-102431-add-age.sql", Checksum: "bbb"},
+		{Version: "20251105-102432-add-index.sql", Checksum: "ccc"},
+		{Version: "R-users-view.sql", Checksum: "ddd", Repeatable: true},
+	}
+	records := []Record{
+		{Version: "20251105-102430-create-users.sql", Status: StatusUp, Checksum: "aaa"},
+		{Version: "20251105-102431-add-age.sql", Status: StatusUp, Checksum: "b00"},
+		// applied by old version, without checksum
+		{Version: "20251105-102432-add-index.sql", Status: StatusUp},
+		{Version: "20251105-102433-deleted.sql", Status: StatusUp, Checksum: "eee"},
+		{Version: "20251105-102434-rolled.sql", Status: StatusDown, Checksum: "fff"},
+		{Version: "R-users-view.sql", Status: StatusUp, Checksum: "d00"},
+		// the Go-code migration is not registered
+		{Version: "20251105-102435-seed-users", Status: StatusUp, Kind: KindGo},
+	}
+
+	drifts := FindDrifts(records, migrations)
+	assert.Len(t, drifts, 2)
+	assert.Eq(t, Drift{Version: "20251105-102431-add-age.sql", Reason: DriftModified, Checksum: "b00", Current: "bbb"}, drifts[0])
+	assert.Eq(t, DriftMissing, drifts[1].Reason)
+	assert.Eq(t, "20251105-102433-deleted.sql", drifts[1].Version)
+
+	statuses := StatusOf(records, migrations)
+	assert.Len(t, statuses, 4)
+	assert.Eq(t, StatusOutdated, statuses[3].Status)
+}
+
+func TestFindOutOfOrder(t *testing.T) {
+	migrations := []*Migration{
+		{Version: "20251105-102430-create-users.sql"},
+		{Version: "20251105-102431-merged-later.sql"},
+		// only for postgres, it is never applied on sqlite
+		{Version: "20251105-102431-pg-only.sql", Options: Options{Drivers: []string{"postgres"}}},
+		{Version: "20251105-102432-rolled.sql"},
+		{Version: "20251105-102433-add-age.sql"},
+		{Version: "20251105-102434-pending.sql"},
+		{Version: "R-users-view.sql", Repeatable: true},
+	}
+	records := []Record{
+		{Version: "20251105-102430-create-users.sql", Status: StatusUp},
+		{Version: "20251105-102432-rolled.sql", Status: StatusDown},
+		{Version: "20251105-102433-add-age.sql", Status: StatusSkip},
+	}
+
+	migs := FindOutOfOrder(records, migrations, "sqlite")
+	assert.Len(t, migs, 2)
+	assert.Eq(t, "20251105-102431-merged-later.sql", migs[0].Version)
+	assert.Eq(t, "20251105-102432-rolled.sql", migs[1].Version)
+	assert.Len(t, FindOutOfOrder(records, migrations, "postgres"), 3)
+
+	// nothing applied
+	assert.Empty(t, FindOutOfOrder(nil, migrations, "sqlite"))
+	// the repeatable migration is always run last
+	assert.Empty(t, FindOutOfOrder([]Record{{Version: "R-users-view.sql", Status: StatusUp}}, migrations, "sqlite"))
+}
+
+func TestParseOutOfOrderPolicy(t *testing.T) {
+	policy, err := ParseOutOfOrderPolicy("")
+	assert.NoErr(t, err)
+	assert.Eq(t, OutOfOrderWarn, policy)
+
+	policy, err = ParseOutOfOrderPolicy(" Error ")
+	assert.NoErr(t, err)
+	assert.Eq(t, OutOfOrderError, policy)
+
+	_, err = ParseOutOfOrderPolicy("ignore")
+	assert.ErrSubMsg(t, err, "invalid out-of-order policy")
+}
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gookit/miglite/pkg/sqlsplit"
+)
+
+// varPattern matches the simple variable placeholder, the leading backslash escapes it. eg: ${schema}, \${schema}
+var varPattern = regexp.MustCompile(`\\?\$\{([A-Za-z_][\w.-]*)}`)
+
+// literalPattern matches the placeholder of the string literal or comment. see protectLiterals
+var literalPattern = regexp.MustCompile("\x00(\\d+)\x00")
+
+// varFuncs the functions can be used in the template
+var varFuncs = template.FuncMap{
+	// quote the value as a SQL string literal. eg: {{ quote .Vars.name }} => 'name'
+	"quote": func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" },
+}
+
+// RenderVars renders the template variables in the SQL text. Supports two syntaxes:
+//
+//	${schema}.users           - simple placeholder, escape it by a backslash: \${schema}
+//	OWNER TO {{ .Vars.owner }} - Go text/template, the data has the field Vars
+//
+// The string literals and comments are kept as is, use {{ quote .Vars.name }} to render a string literal.
+// Substitution is strict, returns an error if the variable is undefined.
+func RenderVars(sqlText string, vars map[string]string) (string, error) {
+	return renderVars(fileLexer, sqlText, vars)
+}
+
+func renderVars(lexer *sqlsplit.Splitter, sqlText string, vars map[string]string) (string, error) {
+	sqlText, literals := protectLiterals(lexer, sqlText)
+	if strings.Contains(sqlText, "{{") {
+		tpl, err := template.New("sql").Funcs(varFuncs).Option("missingkey=error").Parse(sqlText)
+		if err != nil {
+			return "", err
+		}
+
+		var buf strings.Builder
+		if err = tpl.Execute(&buf, struct{ Vars map[string]string }{Vars: vars}); err != nil {
+			return "", err
+		}
+		sqlText = buf.String()
+	}
+
+	var undefined []string
+	sqlText = varPattern.ReplaceAllStringFunc(sqlText, func(s string) string {
+		if s[0] == '\\' {
+			return s[1:]
+		}
+
+		name := s[2 : len(s)-1]
+		val, ok := vars[name]
+		if !ok {
+			undefined = append(undefined, name)
+		}
+		return val
+	})
+
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variable: %s", strings.Join(undefined, ", "))
+	}
+
+	// restore the string literals and comments
+	return literalPattern.ReplaceAllStringFunc(sqlText, func(s string) string {
+		i, _ := strconv.Atoi(s[1 : len(s)-1])
+		return literals[i]
+	}), nil
+}
+
+// protectLiterals replaces the string literals and comments by the placeholders "\x00{index}\x00",
+// so they are not rendered. returns the replaced text and the literals.
+func protectLiterals(lexer *sqlsplit.Splitter, sqlText string) (string, []string) {
+	spans := lexer.LiteralSpans(sqlText)
+	if len(spans) == 0 {
+		return sqlText, nil
+	}
+
+	var sb strings.Builder
+	literals := make([]string, 0, len(spans))
+	last := 0
+	for i, span := range spans {
+		sb.WriteString(sqlText[last:span.Start])
+		sb.WriteString("\x00" + strconv.Itoa(i) + "\x00")
+		literals = append(literals, sqlText[span.Start:span.End])
+		last = span.End
+	}
+	sb.WriteString(sqlText[last:])
+	return sb.String(), literals
+}
+
+// RenderStatements renders the template variables in the UP or DOWN section, then splits it to statements.
+// see RenderVars and Statements
+func (m *Migration) RenderStatements(driver, direction string, vars map[string]string) ([]sqlsplit.Statement, error) {
+	lexer := sqlsplit.ForDriver(driver)
+	if lexer == sqlsplit.Default {
+		lexer = fileLexer
+	}
+
+	section, line := m.section(direction)
+	rendered, err := renderVars(lexer, section, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render migration %s: %v", m.Source(), err)
+	}
+	return splitSection(driver, rendered, line), nil
+}
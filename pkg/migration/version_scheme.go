@@ -0,0 +1,171 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version scheme names
+const (
+	// SchemeTimestampLocal version is the local time. eg: 20251105-102430-{name}.sql
+	SchemeTimestampLocal = "timestamp-local"
+	// SchemeTimestampUTC version is the UTC time. eg: 20251105-102430-{name}.sql
+	SchemeTimestampUTC = "timestamp-utc"
+	// SchemeSequential version is the sequential number. eg: 0001_{name}.sql
+	SchemeSequential = "sequential"
+)
+
+// VersionScheme defines how to generate the version of new migration file, and how to parse the version on discovery.
+//
+// The default formats YYYYMMDD-HHMMSS-{name}.sql and NNNN_{name}.sql are always allowed on discovery,
+// so that the existing files are still valid after change the scheme.
+type VersionScheme struct {
+	// Name of the scheme. see SchemeTimestampLocal
+	Name string
+	// Layout the time layout for timestamp schemes. default is DateLayout
+	Layout string
+	// Digits the min digits of the sequential number. default is 4
+	Digits int
+	// Paths all the configured migration paths. The sequential scheme finds the last number in all of them,
+	// so the files in different paths never get the same number.
+	Paths []string
+}
+
+// DefaultScheme the default version scheme: timestamp-local
+var DefaultScheme = &VersionScheme{Name: SchemeTimestampLocal, Layout: DateLayout}
+
+// ParseVersionScheme parses the version scheme setting. format: NAME[:LAYOUT|DIGITS]
+//
+//	timestamp-local, timestamp-utc, sequential
+//	timestamp-utc:20060102150405 - custom time layout
+//	sequential:6                  - 6 digits number. eg: 000001_{name}.sql
+func ParseVersionScheme(str string) (*VersionScheme, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(str), ":")
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "", "timestamp", SchemeTimestampLocal, SchemeTimestampUTC:
+		if name == "" || name == "timestamp" {
+			name = SchemeTimestampLocal
+		}
+		s := &VersionScheme{Name: name, Layout: DateLayout}
+		if arg != "" {
+			s.Layout = arg
+			if _, err := time.Parse(arg, time.Now().Format(arg)); err != nil {
+				return nil, fmt.Errorf("invalid time layout %q of version scheme: %v", arg, err)
+			}
+		}
+		return s, nil
+	case SchemeSequential:
+		s := &VersionScheme{Name: name, Digits: 4}
+		if arg != "" {
+			digits, err := strconv.Atoi(arg)
+			if err != nil || digits < 1 {
+				return nil, fmt.Errorf("invalid digits %q of sequential version scheme", arg)
+			}
+			s.Digits = digits
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown version scheme %q, allow: %s, %s, %s", str, SchemeTimestampLocal, SchemeTimestampUTC, SchemeSequential)
+}
+
+// IsSequential checks the scheme is sequential
+func (s *VersionScheme) IsSequential() bool { return s.Name == SchemeSequential }
+
+// ParseFilename extracts the time,name from a migration filename.
+// The custom layout of the scheme is tried first, then the default formats.
+func (s *VersionScheme) ParseFilename(filename string) (*FilenameInfo, error) {
+	if s != nil && s.Layout != "" && s.Layout != DateLayout {
+		if fi := s.parseLayout(filename); fi != nil {
+			return fi, nil
+		}
+	}
+	return parseFilename(filename)
+}
+
+// parseLayout parses the filename by the custom layout. format: {LAYOUT}-{name}.sql
+func (s *VersionScheme) parseLayout(filename string) *FilenameInfo {
+	n := len(time.Now().Format(s.Layout))
+	if len(filename) <= n+1 || !strings.HasSuffix(filename, ".sql") {
+		return nil
+	}
+
+	t, err := time.Parse(s.Layout, filename[:n])
+	if err != nil || filename[n] != '-' && filename[n] != '_' {
+		return nil
+	}
+	return &FilenameInfo{Time: t, Date: t.Format(DateLayout), Name: strings.TrimSuffix(filename[n+1:], ".sql")}
+}
+
+// NextFilename generates the file name for the new migration in the directory.
+//
+//   - timestamp schemes: if the version is used in the directory, increase it by 1 second.
+//   - sequential scheme: use the next free number of the files in the directory and the Paths.
+func (s *VersionScheme) NextFilename(migrationsDir, name string) (string, error) {
+	if s.IsSequential() {
+		var last int64
+		for _, dir := range append([]string{migrationsDir}, s.Paths...) {
+			num, err := lastSequence(dir)
+			if err != nil {
+				return "", err
+			}
+			last = max(last, num)
+		}
+		return fmt.Sprintf("%0*d_%s.sql", s.Digits, last+1, name), nil
+	}
+
+	now := s.Now()
+
+	// avoid the same version for files created in the same second
+	for {
+		prefix := now.Format(s.Layout)
+		matches, err := filepath.Glob(filepath.Join(migrationsDir, prefix+"*"))
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 0 {
+			return prefix + "-" + name + ".sql", nil
+		}
+		now = now.Add(time.Second)
+	}
+}
+
+// Now returns the current time, it is UTC time for the scheme timestamp-utc.
+func (s *VersionScheme) Now() time.Time {
+	if s.Name == SchemeTimestampUTC {
+		return time.Now().UTC()
+	}
+	return time.Now()
+}
+
+// match sequential file names. eg: 0001_create_users.sql, 0001_create_users.up.sql
+var regexSequence = regexp.MustCompile(`^(\d+)_[\w-]+(\.up|\.down)?\.sql$`)
+
+// lastSequence finds the max sequential number of the migration files in the directory(include sub directories)
+func lastSequence(migrationsDir string) (int64, error) {
+	var last int64
+	err := filepath.WalkDir(migrationsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if m := regexSequence.FindStringSubmatch(d.Name()); m != nil {
+			if num, err1 := strconv.ParseInt(m[1], 10, 64); err1 == nil && num > last {
+				last = num
+			}
+		}
+		return nil
+	})
+	return last, err
+}
@@ -0,0 +1,57 @@
+package sqlsplit
+
+import "github.com/gookit/miglite/pkg/migcom"
+
+// Splitter splits SQL text to statements by the dialect settings
+type Splitter struct {
+	// Name of the dialect. eg: mysql, postgres
+	Name string
+	// BackslashEscape the backslash escapes the next char in quoted string
+	BackslashEscape bool
+	// Backtick quoted identifier. eg: `name`
+	Backtick bool
+	// Bracket quoted identifier. eg: [name]
+	Bracket bool
+	// HashComment line comment starts with '#'
+	HashComment bool
+	// DollarQuote postgres dollar quoted string. eg: $$ ... $$
+	DollarQuote bool
+	// Delimiter support the DELIMITER command to change the statement delimiter
+	Delimiter bool
+	// TriggerBlock the semicolons in CREATE TRIGGER ... BEGIN ... END are not split
+	TriggerBlock bool
+	// BatchGO split by the GO line only, the semicolons do not split the batch
+	BatchGO bool
+}
+
+// built-in splitters
+var (
+	// Default splitter for common SQL, split by semicolons only.
+	Default = &Splitter{Name: "default", BackslashEscape: true, Backtick: true, HashComment: true}
+	// MySQL splitter
+	MySQL = &Splitter{Name: migcom.DriverMySQL, BackslashEscape: true, Backtick: true, HashComment: true, Delimiter: true}
+	// Postgres splitter
+	Postgres = &Splitter{Name: migcom.DriverPostgres, DollarQuote: true}
+	// SQLite splitter
+	SQLite = &Splitter{Name: migcom.DriverSQLite, Backtick: true, Bracket: true, TriggerBlock: true}
+	// MSSQL splitter
+	MSSQL = &Splitter{Name: migcom.DriverMSSQL, Bracket: true, BatchGO: true}
+)
+
+// ForDriver returns the splitter of the database driver, returns Default if not found.
+//
+//   - driver: formatted driver name. see migcom.DriverMySQL
+func ForDriver(driver string) *Splitter {
+	switch driver {
+	case migcom.DriverMySQL:
+		return MySQL
+	case migcom.DriverPostgres:
+		return Postgres
+	case migcom.DriverSQLite:
+		return SQLite
+	case migcom.DriverMSSQL:
+		return MSSQL
+	default:
+		return Default
+	}
+}
@@ -0,0 +1,190 @@
+package sqlsplit
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+	"github.com/gookit/miglite/pkg/migcom"
+)
+
+type splitCase struct {
+	name string
+	sql  string
+	want []string
+}
+
+func runSplitCases(t *testing.T, s *Splitter, tests []splitCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Eq(t, tt.want, s.Strings(tt.sql))
+		})
+	}
+}
+
+func TestForDriver(t *testing.T) {
+	assert.Eq(t, MySQL, ForDriver(migcom.DriverMySQL))
+	assert.Eq(t, Postgres, ForDriver(migcom.DriverPostgres))
+	assert.Eq(t, SQLite, ForDriver(migcom.DriverSQLite))
+	assert.Eq(t, MSSQL, ForDriver(migcom.DriverMSSQL))
+	assert.Eq(t, Default, ForDriver("oracle"))
+}
+
+func TestSplitter_postgres(t *testing.T) {
+	runSplitCases(t, Postgres, []splitCase{
+		{
+			name: "dollar quoted function body",
+			sql: `CREATE FUNCTION inc(i integer) RETURNS integer AS $$
+BEGIN
+    RETURN i + 1;
+END;
+$$ LANGUAGE plpgsql;
+SELECT inc(1);`,
+			want: []string{
+				"CREATE FUNCTION inc(i integer) RETURNS integer AS $$\nBEGIN\n    RETURN i + 1;\nEND;\n$$ LANGUAGE plpgsql",
+				"SELECT inc(1)",
+			},
+		},
+		{
+			name: "tagged dollar quote",
+			sql:  "DO $body$ BEGIN RAISE NOTICE 'a;b $$'; END $body$; SELECT 1;",
+			want: []string{"DO $body$ BEGIN RAISE NOTICE 'a;b $$'; END $body$", "SELECT 1"},
+		},
+		{
+			name: "positional parameters",
+			sql:  "PREPARE q AS SELECT $1::int; EXECUTE q(1);",
+			want: []string{"PREPARE q AS SELECT $1::int", "EXECUTE q(1)"},
+		},
+		{
+			name: "backslash is literal in standard string",
+			sql:  `INSERT INTO paths VALUES ('C:\'); INSERT INTO t VALUES (E'a\';b');`,
+			want: []string{`INSERT INTO paths VALUES ('C:\')`, `INSERT INTO t VALUES (E'a\';b')`},
+		},
+		{
+			name: "nested quotes",
+			sql:  `CREATE TABLE "user;s" (id int); COMMENT ON TABLE "user;s" IS 'it''s;ok';`,
+			want: []string{`CREATE TABLE "user;s" (id int)`, `COMMENT ON TABLE "user;s" IS 'it''s;ok'`},
+		},
+	})
+}
+
+func TestSplitter_mysql(t *testing.T) {
+	runSplitCases(t, MySQL, []splitCase{
+		{
+			name: "delimiter procedure",
+			sql: `DROP PROCEDURE IF EXISTS add_user;
+DELIMITER //
+CREATE PROCEDURE add_user(IN name VARCHAR(64))
+BEGIN
+    INSERT INTO users(name) VALUES (name);
+    SELECT LAST_INSERT_ID();
+END //
+DELIMITER ;
+CALL add_user('tom');`,
+			want: []string{
+				"DROP PROCEDURE IF EXISTS add_user",
+				"CREATE PROCEDURE add_user(IN name VARCHAR(64))\nBEGIN\n    INSERT INTO users(name) VALUES (name);\n    SELECT LAST_INSERT_ID();\nEND",
+				"CALL add_user('tom')",
+			},
+		},
+		{
+			name: "delimiter trigger",
+			sql:  "delimiter $$\nCREATE TRIGGER trg BEFORE INSERT ON users FOR EACH ROW BEGIN SET NEW.age = 1; END$$\ndelimiter ;\n",
+			want: []string{"CREATE TRIGGER trg BEFORE INSERT ON users FOR EACH ROW BEGIN SET NEW.age = 1; END"},
+		},
+		{
+			name: "backslash escape and hash comment",
+			sql:  "INSERT INTO t VALUES ('a\\';b'); # comment;\nSELECT `a;b` FROM t;",
+			want: []string{"INSERT INTO t VALUES ('a\\';b')", "# comment;\nSELECT `a;b` FROM t"},
+		},
+		{
+			name: "delimiter column is not command",
+			sql:  "CREATE TABLE t (\n  id int,\n  delimiter VARCHAR(10)\n);\nSELECT 1;",
+			want: []string{"CREATE TABLE t (\n  id int,\n  delimiter VARCHAR(10)\n)", "SELECT 1"},
+		},
+		{
+			name: "delimiter after comments",
+			sql:  "-- change delimiter\nDELIMITER //\nSELECT 1//\nDELIMITER ;",
+			want: []string{"SELECT 1"},
+		},
+	})
+}
+
+func TestSplitter_sqlite(t *testing.T) {
+	runSplitCases(t, SQLite, []splitCase{
+		{
+			name: "trigger block",
+			sql: `CREATE TABLE logs(id INTEGER, msg TEXT);
+CREATE TRIGGER IF NOT EXISTS trg_users AFTER INSERT ON users
+BEGIN
+    INSERT INTO logs VALUES (NEW.id, CASE WHEN NEW.age > 18 THEN 'adult' ELSE 'child' END);
+    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+INSERT INTO users(name) VALUES ('a');`,
+			want: []string{
+				"CREATE TABLE logs(id INTEGER, msg TEXT)",
+				"CREATE TRIGGER IF NOT EXISTS trg_users AFTER INSERT ON users\nBEGIN\n    INSERT INTO logs VALUES (NEW.id, CASE WHEN NEW.age > 18 THEN 'adult' ELSE 'child' END);\n    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;\nEND",
+				"INSERT INTO users(name) VALUES ('a')",
+			},
+		},
+		{
+			name: "temp trigger",
+			sql:  "create temp trigger t1 after delete on users begin delete from logs where id = old.id; end; select 1;",
+			want: []string{"create temp trigger t1 after delete on users begin delete from logs where id = old.id; end", "select 1"},
+		},
+		{
+			name: "transaction is not a block",
+			sql:  "BEGIN TRANSACTION; INSERT INTO [my;table] VALUES (1); COMMIT;",
+			want: []string{"BEGIN TRANSACTION", "INSERT INTO [my;table] VALUES (1)", "COMMIT"},
+		},
+	})
+}
+
+func TestSplitter_mssql(t *testing.T) {
+	runSplitCases(t, MSSQL, []splitCase{
+		{
+			name: "go batches",
+			sql: `CREATE TABLE [users] (id INT);
+INSERT INTO [users] VALUES (1);
+GO
+CREATE PROCEDURE get_users AS
+BEGIN
+    SELECT * FROM [users];
+END
+go
+`,
+			want: []string{
+				"CREATE TABLE [users] (id INT);\nINSERT INTO [users] VALUES (1);",
+				"CREATE PROCEDURE get_users AS\nBEGIN\n    SELECT * FROM [users];\nEND",
+			},
+		},
+		{
+			name: "go in string and identifier",
+			sql:  "SELECT 'x\nGO\ny' AS [GO];\nGO 2\nSELECT 2",
+			want: []string{"SELECT 'x\nGO\ny' AS [GO];", "SELECT 'x\nGO\ny' AS [GO];", "SELECT 2"},
+		},
+		{
+			name: "go column is not batch separator",
+			sql:  "CREATE TABLE t (\n  id INT,\n  go INT\n)\nGO\nSELECT 1",
+			want: []string{"CREATE TABLE t (\n  id INT,\n  go INT\n)", "SELECT 1"},
+		},
+	})
+}
+
+func TestSplitter_line(t *testing.T) {
+	statements := MySQL.Split("DELIMITER //\n\nCREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND //\nDELIMITER ;\nSELECT 2;")
+	assert.Len(t, statements, 2)
+	assert.Eq(t, 3, statements[0].Line)
+	assert.Eq(t, 8, statements[1].Line)
+
+	statements = MSSQL.Split("SELECT 1\nGO\n\nSELECT 2")
+	assert.Len(t, statements, 2)
+	assert.Eq(t, 4, statements[1].Line)
+
+	// the count of GO N is kept on the batch
+	statements = MSSQL.Split("INSERT INTO t VALUES (1)\nGO 3\nSELECT 2\nGO")
+	assert.Len(t, statements, 2)
+	assert.Eq(t, 3, statements[0].Count)
+	assert.Eq(t, 3, statements[0].Times())
+	assert.Eq(t, 1, statements[1].Times())
+}
@@ -0,0 +1,427 @@
+// Package sqlsplit splits SQL text into statements without breaking quoted or commented semicolons.
+//
+// The Splitter is dialect-aware, use ForDriver to get the splitter of a database driver:
+//
+//   - postgres: dollar quoted strings. eg: $$ ... $$, $body$ ... $body$
+//   - mysql: DELIMITER command, # comments, backslash escapes
+//   - sqlite: CREATE TRIGGER ... BEGIN ... END;
+//   - mssql: GO batch separator, semicolons do not split the batch
+package sqlsplit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Statement is a SQL statement split from the SQL text
+type Statement struct {
+	// SQL the statement text, without the ending semicolon
+	SQL string
+	// Line the line number(1-based) of the statement start in the SQL text. leading comments are not counted.
+	Line int
+	// Count the times to run the statement, set by the mssql batch separator with count. eg: GO 2
+	// 0 means run once. see Times
+	Count int
+}
+
+// Times returns the times to run the statement, at least 1.
+func (st Statement) Times() int {
+	return max(st.Count, 1)
+}
+
+// LineComment is a top-level line comment in the SQL text, not inside a string or block comment.
+type LineComment struct {
+	// Text of the comment, include the leading "--" or "#", without the newline
+	Text string
+	// Line number(1-based) of the comment
+	Line int
+	// Offset the byte offset of the comment start, End the offset after the comment line(include newline)
+	Offset, End int
+}
+
+// Span is a byte range [Start, End) in the SQL text
+type Span struct {
+	Start, End int
+}
+
+// AtLineStart checks the comment is at the start of line, only whitespace before it.
+func (c LineComment) AtLineStart(sqlText string) bool {
+	lineStart := strings.LastIndexByte(sqlText[:c.Offset], '\n') + 1
+	return strings.TrimSpace(sqlText[lineStart:c.Offset]) == ""
+}
+
+// Split splits common SQL by the Default splitter. see Splitter.Split
+func Split(sqlText string) []Statement {
+	return Default.Split(sqlText)
+}
+
+// Strings splits common SQL by the Default splitter, returns the statement strings.
+func Strings(sqlText string) []string {
+	return Default.Strings(sqlText)
+}
+
+// Split splits the SQL text to statements.
+func (s *Splitter) Split(sqlText string) []Statement {
+	sc := &scanner{Splitter: s, text: sqlText, line: 1, delimiter: ";"}
+	return sc.scan()
+}
+
+// LineComments returns the top-level line comments in the SQL text.
+func (s *Splitter) LineComments(sqlText string) []LineComment {
+	var comments []LineComment
+	sc := &scanner{Splitter: s, text: sqlText, line: 1, delimiter: ";"}
+	sc.onLineComment = func(start, end, line int) {
+		text := strings.TrimRight(sqlText[start:end], "\r\n")
+		comments = append(comments, LineComment{Text: text, Line: line, Offset: start, End: end})
+	}
+
+	sc.scan()
+	return comments
+}
+
+// LiteralSpans returns the ranges of the string literals and comments in the SQL text.
+// eg: 'text', E'text', $$ body $$, -- comment, /* comment */. Quoted identifiers are not included.
+func (s *Splitter) LiteralSpans(sqlText string) []Span {
+	var spans []Span
+	sc := &scanner{Splitter: s, text: sqlText, line: 1, delimiter: ";"}
+	sc.onLiteral = func(start, end int) {
+		spans = append(spans, Span{Start: start, End: end})
+	}
+
+	sc.scan()
+	return spans
+}
+
+// Strings splits the SQL text, returns the statement strings. see Split
+//
+// The statement is repeated by its Count. eg: the batch before "GO 2" is returned twice.
+func (s *Splitter) Strings(sqlText string) []string {
+	statements := s.Split(sqlText)
+	ss := make([]string, 0, len(statements))
+	for _, st := range statements {
+		for i := 0; i < st.Times(); i++ {
+			ss = append(ss, st.SQL)
+		}
+	}
+	return ss
+}
+
+// scanner for split SQL text
+type scanner struct {
+	*Splitter
+	text string
+	pos  int
+	// current line number
+	line int
+	buf  strings.Builder
+	// the start line of current statement, 0 is not started
+	startLine int
+	// delimiter of statements, can be changed by the DELIMITER command
+	delimiter string
+	// words in current statement before the block begin, for detect the block statement. eg: CREATE TRIGGER
+	words []string
+	// depth of the BEGIN...END block and CASE...END in the block
+	blockDepth, caseDepth int
+	// writing comment, the statement start line is not set by comments
+	inComment bool
+	// onLineComment hook for the top-level line comments
+	onLineComment func(start, end, line int)
+	// onLiteral hook for the string literals and comments
+	onLiteral func(start, end int)
+
+	statements []Statement
+}
+
+func (sc *scanner) scan() []Statement {
+	for sc.pos < len(sc.text) {
+		if sc.atLineStart() && sc.scanLineCommand() {
+			continue
+		}
+
+		ch, start := sc.text[sc.pos], sc.pos
+		switch {
+		case !sc.BatchGO && sc.blockDepth == 0 && strings.HasPrefix(sc.text[sc.pos:], sc.delimiter):
+			sc.pos += len(sc.delimiter)
+			sc.flush()
+		case ch == '\'':
+			escape := sc.BackslashEscape || sc.DollarQuote && sc.pos > 0 && (sc.text[sc.pos-1] == 'E' || sc.text[sc.pos-1] == 'e')
+			sc.scanQuoted('\'', escape)
+			sc.literal(start)
+		case ch == '"':
+			sc.scanQuoted('"', sc.BackslashEscape)
+		case ch == '`' && sc.Backtick:
+			sc.scanQuoted('`', false)
+		case ch == '[' && sc.Bracket:
+			sc.scanQuoted(']', false)
+		case ch == '$' && sc.DollarQuote && sc.scanDollarQuoted():
+			sc.literal(start)
+		case ch == '-' && sc.peek(1) == '-', ch == '#' && sc.HashComment:
+			line := sc.line
+			sc.scanComment("\n")
+			if sc.onLineComment != nil {
+				sc.onLineComment(start, sc.pos, line)
+			}
+			sc.literal(start)
+		case ch == '/' && sc.peek(1) == '*':
+			sc.scanComment("*/")
+			sc.literal(start)
+		case isWordChar(ch):
+			sc.scanWord()
+		default:
+			sc.write(sc.text[sc.pos : sc.pos+1])
+			sc.pos++
+		}
+	}
+
+	sc.flush()
+	return sc.statements
+}
+
+// literal calls the onLiteral hook for the scanned literal or comment from start to current position
+func (sc *scanner) literal(start int) {
+	if sc.onLiteral != nil {
+		sc.onLiteral(start, sc.pos)
+	}
+}
+
+func (sc *scanner) peek(n int) byte {
+	if sc.pos+n < len(sc.text) {
+		return sc.text[sc.pos+n]
+	}
+	return 0
+}
+
+func (sc *scanner) atLineStart() bool {
+	return sc.pos == 0 || sc.text[sc.pos-1] == '\n'
+}
+
+// write the text to current statement
+func (sc *scanner) write(s string) {
+	if sc.startLine == 0 && !sc.inComment {
+		if trimmed := strings.TrimLeft(s, " \t\r\n"); trimmed != "" {
+			sc.startLine = sc.line + strings.Count(s[:len(s)-len(trimmed)], "\n")
+		}
+	}
+
+	sc.buf.WriteString(s)
+	sc.line += strings.Count(s, "\n")
+}
+
+// flush current statement
+func (sc *scanner) flush() {
+	if statement := strings.TrimSpace(sc.buf.String()); statement != "" && trimLeadingSQLComments(statement) != "" {
+		sc.statements = append(sc.statements, Statement{SQL: statement, Line: sc.startLine})
+	}
+
+	sc.buf.Reset()
+	sc.startLine = 0
+	sc.words = sc.words[:0]
+	sc.blockDepth, sc.caseDepth = 0, 0
+}
+
+// scanLineCommand scan the client command lines: DELIMITER, GO
+//
+//   - DELIMITER: only at the start of statement, eg: not a column named delimiter in CREATE TABLE
+//   - GO: ends the batch, allow the optional count to run the batch N times. eg: GO 2
+func (sc *scanner) scanLineCommand() bool {
+	if !sc.Delimiter && !sc.BatchGO {
+		return false
+	}
+
+	lineText := sc.text[sc.pos:]
+	if end := strings.IndexByte(lineText, '\n'); end >= 0 {
+		lineText = lineText[:end+1]
+	}
+
+	fields := strings.Fields(lineText)
+	if len(fields) == 0 {
+		return false
+	}
+
+	cmd := strings.ToUpper(fields[0])
+	switch {
+	case sc.Delimiter && cmd == "DELIMITER" && len(fields) == 2 && sc.startLine == 0:
+		sc.flush()
+		sc.delimiter = fields[1]
+	case sc.BatchGO && cmd == "GO" && (len(fields) == 1 || len(fields) == 2 && isDigits(fields[1])):
+		n := len(sc.statements)
+		sc.flush()
+		if len(fields) == 2 && len(sc.statements) > n {
+			sc.statements[n].Count, _ = strconv.Atoi(fields[1])
+		}
+	default:
+		return false
+	}
+
+	sc.pos += len(lineText)
+	sc.line += strings.Count(lineText, "\n")
+	return true
+}
+
+// scanQuoted scan the quoted string or identifier, the end quote can be escaped by double it.
+func (sc *scanner) scanQuoted(endQuote byte, backslashEscape bool) {
+	i := sc.pos + 1
+	for i < len(sc.text) {
+		ch := sc.text[i]
+		if backslashEscape && ch == '\\' {
+			i += 2
+			continue
+		}
+		if ch == endQuote {
+			if i+1 < len(sc.text) && sc.text[i+1] == endQuote {
+				i += 2
+				continue
+			}
+			i++
+			break
+		}
+		i++
+	}
+
+	sc.writeTo(i)
+}
+
+// scanDollarQuoted scan the postgres dollar quoted string. eg: $$ ... $$, $tag$ ... $tag$
+func (sc *scanner) scanDollarQuoted() bool {
+	// $1 is a parameter, the tag cannot start with a digit
+	if sc.pos > 0 && isWordChar(sc.text[sc.pos-1]) {
+		return false
+	}
+
+	end := strings.IndexByte(sc.text[sc.pos+1:], '$')
+	if end < 0 {
+		return false
+	}
+
+	tag := sc.text[sc.pos : sc.pos+end+2]
+	for i := 1; i < len(tag)-1; i++ {
+		if !isWordChar(tag[i]) || i == 1 && tag[i] >= '0' && tag[i] <= '9' {
+			return false
+		}
+	}
+
+	closeAt := strings.Index(sc.text[sc.pos+len(tag):], tag)
+	if closeAt < 0 {
+		sc.writeTo(len(sc.text))
+	} else {
+		sc.writeTo(sc.pos + len(tag) + closeAt + len(tag))
+	}
+	return true
+}
+
+// scanComment scan the comment until the end string(included).
+func (sc *scanner) scanComment(end string) {
+	sc.inComment = true
+	defer func() { sc.inComment = false }()
+
+	idx := strings.Index(sc.text[sc.pos+1:], end)
+	if idx < 0 {
+		sc.writeTo(len(sc.text))
+		return
+	}
+	sc.writeTo(sc.pos + 1 + idx + len(end))
+}
+
+// scanWord scan a keyword or identifier, and track the BEGIN...END block.
+func (sc *scanner) scanWord() {
+	i := sc.pos
+	for i < len(sc.text) && isWordChar(sc.text[i]) {
+		i++
+	}
+
+	word := strings.ToUpper(sc.text[sc.pos:i])
+	sc.writeTo(i)
+	if !sc.TriggerBlock {
+		return
+	}
+
+	if sc.blockDepth == 0 {
+		if len(sc.words) < 3 {
+			sc.words = append(sc.words, word)
+		}
+		if word == "BEGIN" && sc.isBlockStatement() {
+			sc.blockDepth++
+		}
+		return
+	}
+
+	switch word {
+	case "BEGIN":
+		sc.blockDepth++
+	case "CASE":
+		sc.caseDepth++
+	case "END":
+		if sc.caseDepth > 0 {
+			sc.caseDepth--
+		} else {
+			sc.blockDepth--
+		}
+	}
+}
+
+// isBlockStatement check current statement is: CREATE [TEMP|TEMPORARY] TRIGGER
+func (sc *scanner) isBlockStatement() bool {
+	if len(sc.words) < 2 || sc.words[0] != "CREATE" {
+		return false
+	}
+	return sc.words[1] == "TRIGGER" || len(sc.words) > 2 && sc.words[2] == "TRIGGER"
+}
+
+func (sc *scanner) writeTo(end int) {
+	if end > len(sc.text) {
+		end = len(sc.text)
+	}
+	sc.write(sc.text[sc.pos:end])
+	sc.pos = end
+}
+
+// IsQuery checks the SQL is a query statement that returns rows. eg: SELECT, SHOW
+func IsQuery(sqlText string) bool {
+	sqlText = strings.ToLower(trimLeadingSQLComments(sqlText))
+	for _, keyword := range []string{"select", "describe", "pragma", "show"} {
+		if strings.HasPrefix(sqlText, keyword) &&
+			(len(sqlText) == len(keyword) || !isSQLWordChar(sqlText[len(keyword)])) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimLeadingSQLComments(sqlText string) string {
+	for {
+		sqlText = strings.TrimSpace(sqlText)
+		switch {
+		case strings.HasPrefix(sqlText, "--"), strings.HasPrefix(sqlText, "#"):
+			if end := strings.IndexByte(sqlText, '\n'); end >= 0 {
+				sqlText = sqlText[end+1:]
+				continue
+			}
+			return ""
+		case strings.HasPrefix(sqlText, "/*"):
+			if end := strings.Index(sqlText[2:], "*/"); end >= 0 {
+				sqlText = sqlText[end+4:]
+				continue
+			}
+			return ""
+		default:
+			return sqlText
+		}
+	}
+}
+
+func isWordChar(ch byte) bool {
+	return isSQLWordChar(ch) || ch >= 'A' && ch <= 'Z'
+}
+
+func isSQLWordChar(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9'
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,104 @@
+package sqlsplit
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/x/assert"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "two statements",
+			sql:  "CREATE TABLE users(id int); INSERT INTO users VALUES (1);",
+			want: []string{"CREATE TABLE users(id int)", "INSERT INTO users VALUES (1)"},
+		},
+		{
+			name: "quoted semicolons",
+			sql:  "INSERT INTO logs VALUES ('a;b', \"c;d\", `e;f`); SELECT 1;",
+			want: []string{"INSERT INTO logs VALUES ('a;b', \"c;d\", `e;f`)", "SELECT 1"},
+		},
+		{
+			name: "comment semicolons",
+			sql:  "-- keep ; here\nSELECT 1; /* keep ; here */ SELECT 2;",
+			want: []string{"-- keep ; here\nSELECT 1", "/* keep ; here */ SELECT 2"},
+		},
+		{name: "empty statements", sql: "; SELECT 1;;", want: []string{"SELECT 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Eq(t, tt.want, Strings(tt.sql))
+		})
+	}
+}
+
+func TestSplit_line(t *testing.T) {
+	sqlText := "\n-- create table\nCREATE TABLE users(\n  id int\n);\n\nINSERT INTO users VALUES ('a\nb'); SELECT 1;\n"
+	statements := Split(sqlText)
+	assert.Len(t, statements, 3)
+	// leading comments are not counted
+	assert.Eq(t, 3, statements[0].Line)
+	assert.Eq(t, "-- create table\nCREATE TABLE users(\n  id int\n)", statements[0].SQL)
+	assert.Eq(t, 7, statements[1].Line)
+	assert.Eq(t, 8, statements[2].Line)
+	assert.Eq(t, "SELECT 1", statements[2].SQL)
+}
+
+func TestLineComments(t *testing.T) {
+	sqlText := `-- Migrate:UP
+INSERT INTO notes VALUES ('line1
+-- Migrate:DOWN inside string
+line3'); /* block
+-- Migrate:DOWN inside block */
+SELECT 1; -- tail comment
+  -- Migrate:DOWN
+DROP TABLE notes;`
+
+	assert.Len(t, Split(sqlText), 3)
+
+	lineComments := Default.LineComments(sqlText)
+	assert.Len(t, lineComments, 3)
+	assert.Eq(t, "-- Migrate:UP", lineComments[0].Text)
+	assert.Eq(t, 1, lineComments[0].Line)
+	assert.True(t, lineComments[0].AtLineStart(sqlText))
+
+	assert.Eq(t, "-- tail comment", lineComments[1].Text)
+	assert.Eq(t, 6, lineComments[1].Line)
+	assert.False(t, lineComments[1].AtLineStart(sqlText))
+
+	assert.Eq(t, "-- Migrate:DOWN", lineComments[2].Text)
+	assert.Eq(t, 7, lineComments[2].Line)
+	assert.True(t, lineComments[2].AtLineStart(sqlText))
+	assert.Eq(t, "DROP TABLE notes;", sqlText[lineComments[2].End:])
+}
+
+func TestLiteralSpans(t *testing.T) {
+	sqlText := "SELECT 'a''b', \"col\" -- tail\nFROM t /* block */ WHERE x = $$ body $$;"
+	var literals []string
+	for _, span := range Postgres.LiteralSpans(sqlText) {
+		literals = append(literals, sqlText[span.Start:span.End])
+	}
+	assert.Eq(t, []string{"'a''b'", "-- tail\n", "/* block */", "$$ body $$"}, literals)
+}
+
+func TestIsQuery(t *testing.T) {
+	tests := map[string]bool{
+		" SELECT 1":                  true,
+		"-- comment\nDESCRIBE users": true,
+		"/* comment */ PRAGMA info":  true,
+		"# comment\nSHOW TABLES":     true,
+		"SELECTED value":             false,
+		"UPDATE users SET id = 1":    false,
+	}
+
+	for sqlText, want := range tests {
+		t.Run(sqlText, func(t *testing.T) {
+			assert.Eq(t, want, IsQuery(sqlText))
+		})
+	}
+}